@@ -2,22 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
 	"github.com/YelzhanWeb/pizzas/internal/adapter/postgres"
 	"github.com/YelzhanWeb/pizzas/internal/adapter/rabbitmq"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
+	"github.com/YelzhanWeb/pizzas/internal/app/admission"
+	"github.com/YelzhanWeb/pizzas/internal/app/idempotency"
 	"github.com/YelzhanWeb/pizzas/internal/app/kitchen"
+	"github.com/YelzhanWeb/pizzas/internal/app/menu"
 	"github.com/YelzhanWeb/pizzas/internal/app/order"
+	"github.com/YelzhanWeb/pizzas/internal/app/outbox"
+	"github.com/YelzhanWeb/pizzas/internal/app/promotion"
 	"github.com/YelzhanWeb/pizzas/internal/app/tracking"
 	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 
 	amqpAdapter "github.com/YelzhanWeb/pizzas/internal/adapter/amqp"
 	httpAdapter "github.com/YelzhanWeb/pizzas/internal/adapter/http"
@@ -25,18 +37,26 @@ import (
 
 func main() {
 	// Parse command-line flags
-	mode := flag.String("mode", "", "Service mode: order-service, kitchen-worker, tracking-service, notification-subscriber")
+	mode := flag.String("mode", "", "Service mode: order-service, kitchen-worker, tracking-service, notification-subscriber, migrate-kitchen-queues")
 	port := flag.Int("port", 3000, "HTTP port")
 	workerName := flag.String("worker-name", "", "Worker name (for kitchen-worker)")
 	orderTypes := flag.String("order-types", "", "Comma-separated order types (for kitchen-worker)")
 	heartbeatInterval := flag.Int("heartbeat-interval", 30, "Heartbeat interval in seconds")
 	prefetch := flag.Int("prefetch", 1, "RabbitMQ prefetch count")
 	maxConcurrent := flag.Int("max-concurrent", 50, "Max concurrent orders")
+	ovenSlots := flag.Int("oven-slots", 1, "Orders a kitchen worker cooks at the same time (for kitchen-worker)")
+	adminPort := flag.Int("admin-port", 0, "Side HTTP port for /metrics, /healthz and /readyz in kitchen-worker and notification-subscriber modes (0 disables it)")
 	flag.Parse()
 
 	if *mode == "" {
 		log.Fatal("--mode flag is required")
 	}
+	if *maxConcurrent < 1 {
+		log.Fatal("--max-concurrent must be at least 1")
+	}
+	if *ovenSlots < 1 {
+		log.Fatal("--oven-slots must be at least 1")
+	}
 
 	// Load configuration
 	cfg, err := config.Load("config.yaml")
@@ -44,20 +64,32 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if cfg.RabbitMQ.MaxPriority < 0 || cfg.RabbitMQ.MaxPriority > 255 {
+		log.Fatalf("Invalid rabbitmq.max_priority %d: expected 0-255", cfg.RabbitMQ.MaxPriority)
+	}
+
 	// Setup infrastructure
 	ctx := context.Background()
 
 	// Initialize logger
 	lgr := logger.New(*mode)
 
+	// Initialize tracing: the tracer travels in the context to consumers and background jobs
+	tracer := newTracer(cfg, *mode)
+	ctx = tracing.WithTracer(ctx, tracer)
+
 	// Connect to PostgreSQL
 	db, err := postgres.Connect(ctx, cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
 	}
 	defer db.Close()
+	postgres.RegisterPoolMetrics(metrics.Default, db)
+	if tracer != nil {
+		db = postgres.NewTracedDB(db)
+	}
 
-	lgr.Info("db_connected", "Connected to PostgreSQL database", "startup", map[string]interface{}{
+	lgr.Info(ctx, "db_connected", "Connected to PostgreSQL database", map[string]interface{}{
 		"host": cfg.Database.Host,
 		"db":   cfg.Database.Database,
 	})
@@ -69,62 +101,264 @@ func main() {
 	}
 	defer mqConn.Close()
 
-	lgr.Info("rabbitmq_connected", "Connected to RabbitMQ", "startup", map[string]interface{}{
+	lgr.Info(ctx, "rabbitmq_connected", "Connected to RabbitMQ", map[string]interface{}{
 		"host": cfg.RabbitMQ.Host,
 	})
 
 	// Route to appropriate service
 	switch *mode {
 	case "order-service":
-		runOrderService(ctx, db, mqConn, lgr, *port, *maxConcurrent)
+		runOrderService(ctx, cfg, db, mqConn, lgr, *port, *maxConcurrent)
 
 	case "kitchen-worker":
 		if *workerName == "" {
 			log.Fatal("--worker-name is required for kitchen-worker mode")
 		}
-		runKitchenWorker(ctx, db, mqConn, lgr, *workerName, *orderTypes, *heartbeatInterval, *prefetch)
+		types, err := domain.ParseOrderTypes(*orderTypes)
+		if err != nil {
+			log.Fatalf("Invalid --order-types: %v", err)
+		}
+		runKitchenWorker(ctx, cfg, db, mqConn, lgr, *workerName, types, *heartbeatInterval, *prefetch, *ovenSlots, *adminPort)
 
 	case "tracking-service":
-		runTrackingService(ctx, db, lgr, *port)
+		runTrackingService(ctx, cfg, db, lgr, *port)
 
 	case "notification-subscriber":
-		runNotificationSubscriber(ctx, mqConn, lgr)
+		runNotificationSubscriber(ctx, cfg, mqConn, lgr, *adminPort)
+
+	case "migrate-kitchen-queues":
+		runKitchenQueueMigration(ctx, cfg, mqConn, lgr)
 
 	default:
 		log.Fatalf("Invalid mode: %s", *mode)
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		lgr.Error(ctx, "shutdown_error", "Failed to flush traces", nil, err)
+	}
+}
+
+// newTracer builds the span exporter from the tracing section of config.yaml; nil disables tracing
+func newTracer(cfg *config.Config, service string) *tracing.Tracer {
+	if !cfg.Tracing.Enabled {
+		return nil
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "file":
+		exporter, err := tracing.NewFileExporter(cfg.Tracing.File)
+		if err != nil {
+			log.Fatalf("Invalid tracing config: %v", err)
+		}
+		return tracing.NewTracer(service, exporter)
+	case "http":
+		if cfg.Tracing.Endpoint == "" {
+			log.Fatal("Invalid tracing config: endpoint is required for the http exporter")
+		}
+		return tracing.NewTracer(service, tracing.NewHTTPExporter(cfg.Tracing.Endpoint))
+	default:
+		log.Fatalf("Invalid tracing config: unknown exporter %q", cfg.Tracing.Exporter)
+		return nil
+	}
+}
+
+// pricingRules builds order pricing rules from the pricing section of config.yaml
+func pricingRules(cfg *config.Config) (domain.PricingRules, error) {
+	rules := domain.PricingRules{
+		Currency:         cfg.Money.Currency,
+		ServiceChargeBps: int64(cfg.Pricing.ServiceChargeBps),
+	}
+
+	if cfg.Pricing.DeliveryFee != "" {
+		fee, err := domain.ParseMoney(cfg.Pricing.DeliveryFee.String(), cfg.Money.Currency)
+		if err != nil {
+			return rules, fmt.Errorf("delivery_fee: %w", err)
+		}
+		rules.DeliveryFee = fee
+	}
+
+	for _, spec := range strings.Split(cfg.Pricing.Taxes, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, rate, ok := strings.Cut(spec, ":")
+		bps, err := strconv.ParseInt(strings.TrimSpace(rate), 10, 64)
+		if !ok || name == "" || err != nil || bps < 0 {
+			return rules, fmt.Errorf("invalid tax %q, expected NAME:RATE_BPS", spec)
+		}
+		rules.Taxes = append(rules.Taxes, domain.TaxRule{Name: strings.TrimSpace(name), RateBps: bps})
+	}
+
+	return rules, nil
+}
+
+// orderValidator builds the shared order validation rules from the validation section of config.yaml
+func orderValidator(cfg *config.Config) *order.Validator {
+	limits := order.Limits{
+		CustomerNameMax:    cfg.Validation.CustomerNameMax,
+		DeliveryAddressMin: cfg.Validation.DeliveryAddressMin,
+		DeliveryAddressMax: cfg.Validation.DeliveryAddressMax,
+		TableMin:           cfg.Validation.TableMin,
+		TableMax:           cfg.Validation.TableMax,
+		MaxItems:           cfg.Validation.MaxItems,
+		MaxQuantity:        cfg.Validation.MaxQuantity,
+		ItemNameMax:        order.DefaultLimits().ItemNameMax,
+	}
+
+	var err error
+	if limits.MinItemPrice, err = domain.ParseMoney(cfg.Validation.MinItemPrice.String(), cfg.Money.Currency); err != nil {
+		log.Fatalf("Invalid validation.min_item_price: %v", err)
+	}
+	if limits.MaxItemPrice, err = domain.ParseMoney(cfg.Validation.MaxItemPrice.String(), cfg.Money.Currency); err != nil {
+		log.Fatalf("Invalid validation.max_item_price: %v", err)
+	}
+	if limits.TableMin > limits.TableMax || limits.DeliveryAddressMin > limits.DeliveryAddressMax || limits.MinItemPrice.Cmp(limits.MaxItemPrice) > 0 {
+		log.Fatalf("Invalid validation config: minimum exceeds maximum")
+	}
+
+	return order.NewValidator(limits)
+}
+
+// rateLimited wraps the handler with per-client rate limiting from the rate_limit section of config.yaml
+func rateLimited(cfg *config.Config, lgr logger.Logger, next http.Handler) http.Handler {
+	if !cfg.RateLimit.Enabled {
+		return next
+	}
+
+	rps, err := cfg.RateLimit.RequestsPerSecond.Float64()
+	if err != nil {
+		log.Fatalf("Invalid rate_limit.requests_per_second: %v", err)
+	}
+	routes, err := cfg.RateLimit.RouteLimits()
+	if err != nil {
+		log.Fatalf("Invalid rate_limit.routes: %v", err)
+	}
+
+	limiter := httpAdapter.NewRateLimiter(httpAdapter.RateLimit{RequestsPerSecond: rps, Burst: cfg.RateLimit.Burst}, routes, cfg.RateLimit.TrustForwardedFor)
+	return httpAdapter.RateLimitMiddleware(limiter, lgr)(next)
+}
+
+// authFailuresLimited throttles requests with bad credentials per client IP; it wraps AuthMiddleware,
+// which rejects them before the regular rate limiter runs
+func authFailuresLimited(cfg *config.Config, lgr logger.Logger, next http.Handler) http.Handler {
+	if !cfg.RateLimit.Enabled {
+		return next
+	}
+
+	perMinute := cfg.RateLimit.AuthFailuresPerMinute
+	limiter := httpAdapter.NewRateLimiter(httpAdapter.RateLimit{RequestsPerSecond: float64(perMinute) / 60, Burst: perMinute}, nil, cfg.RateLimit.TrustForwardedFor)
+	return httpAdapter.AuthFailureLimitMiddleware(limiter, lgr)(next)
 }
 
-func runOrderService(ctx context.Context, db postgres.DB, mqConn rabbitmq.Connection, lgr logger.Logger, port, maxConcurrent int) {
+// newAuthenticator builds API key / JWT authentication from the auth section of config.yaml
+func newAuthenticator(cfg *config.Config) *httpAdapter.Authenticator {
+	auth, err := httpAdapter.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Invalid auth config: %v", err)
+	}
+	return auth
+}
+
+func runOrderService(ctx context.Context, cfg *config.Config, db postgres.DB, mqConn rabbitmq.Connection, lgr logger.Logger, port, maxConcurrent int) {
 	// Initialize repositories
 	orderRepo := postgres.NewOrderRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+	menuRepo := postgres.NewMenuRepository(db)
+	promoRepo := postgres.NewPromotionRepository(db)
 
 	// Initialize messaging
 	publisher := rabbitmq.NewPublisher(mqConn)
 
+	// Kitchen queues must exist before the first order is published, or it would be dropped as unroutable
+	if err := rabbitmq.DeclareOrdersTopology(mqConn, cfg.RabbitMQ); err != nil {
+		log.Fatalf("Failed to declare orders topology: %v", err)
+	}
+
+	pricing, err := pricingRules(cfg)
+	if err != nil {
+		log.Fatalf("Invalid pricing config: %v", err)
+	}
+
+	validator := orderValidator(cfg)
+
 	// Initialize service
-	orderService := order.NewService(orderRepo, publisher, lgr)
+	orderService := order.NewService(orderRepo, menuRepo, promoRepo, publisher, validator, lgr, domain.OrderNumberFormat{
+		Prefix:       cfg.OrderNumber.Prefix,
+		LocationCode: cfg.OrderNumber.LocationCode,
+		Padding:      cfg.OrderNumber.Padding,
+	}, pricing)
+	idempotencyService := idempotency.NewService(idempotencyRepo, lgr)
+	menuService := menu.NewService(menuRepo, lgr)
+	promotionService := promotion.NewService(promoRepo, lgr)
+
+	// Background workers stop before the HTTP server shuts down
+	backgroundCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+
+	// Start outbox relay: publishes committed orders to the kitchen
+	relay := outbox.NewRelay(outboxRepo, publisher, lgr, cfg.Outbox)
+	go relay.Run(backgroundCtx)
 
 	// Initialize HTTP handler
-	orderHandler := httpAdapter.NewOrderHandler(orderService, lgr)
+	orderHandler := httpAdapter.NewOrderHandler(orderService, idempotencyService, validator, lgr)
+	menuHandler := httpAdapter.NewMenuHandler(menuService, lgr)
+	promotionHandler := httpAdapter.NewPromotionHandler(promotionService, lgr)
+
+	// Admission control: reject new orders while the kitchen queue is saturated
+	retryAfter := time.Duration(cfg.Admission.RetryAfterSeconds) * time.Second
+	createOrder := http.Handler(http.HandlerFunc(orderHandler.CreateOrder))
+	if cfg.Admission.MaxKitchenBacklog > 0 {
+		backlog := admission.NewBacklogMonitor(rabbitmq.NewQueueInspector(mqConn), lgr, cfg.Admission, rabbitmq.KitchenQueues())
+		go backlog.Run(backgroundCtx)
+		createOrder = httpAdapter.KitchenBacklogGuard(backlog, retryAfter, lgr)(createOrder)
+	}
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
-	mux.HandleFunc("/orders", orderHandler.CreateOrder)
+	auth := newAuthenticator(cfg)
+	mux.Handle("/orders", auth.Require(domain.RoleCashier)(createOrder))
+	mux.Handle("/orders/", auth.RequireFunc(orderHandler.HandleOrderActions, domain.RoleCashier))
+	mux.Handle("/menu", auth.RequireFunc(menuHandler.GetMenu, domain.RoleCustomer, domain.RoleCashier, domain.RoleKitchen))
+	mux.Handle("/admin/menu", auth.RequireFunc(menuHandler.HandleAdminMenu, domain.RoleAdmin))
+	mux.Handle("/admin/menu/", auth.RequireFunc(menuHandler.HandleAdminMenuItem, domain.RoleAdmin))
+	mux.Handle("/admin/promotions", auth.RequireFunc(promotionHandler.HandleAdminPromotions, domain.RoleAdmin))
+	mux.Handle("/admin/promotions/", auth.RequireFunc(promotionHandler.HandleAdminPromotion, domain.RoleAdmin))
+	mux.Handle("/metrics", metrics.Default.Handler())
 
 	// Apply middleware
-	handler := httpAdapter.LoggingMiddleware(lgr)(mux)
+	handler := httpAdapter.ConcurrencyLimitMiddleware(maxConcurrent, retryAfter, lgr)(mux)
+	// Rate limiting runs after auth so buckets are keyed by the authenticated client
+	handler = rateLimited(cfg, lgr, handler)
+	handler = httpAdapter.AuthMiddleware(auth, lgr)(handler)
+	handler = authFailuresLimited(cfg, lgr, handler)
+	handler = httpAdapter.LoggingMiddleware(lgr)(handler)
 	handler = httpAdapter.RecoveryMiddleware(lgr)(handler)
+	handler = httpAdapter.MetricsMiddleware(mux)(handler)
+	handler = httpAdapter.TracingMiddleware(tracing.FromContext(ctx))(handler)
+	handler = httpAdapter.RequestIDMiddleware(handler)
+
+	// Health probes bypass auth, rate limiting and the concurrency limit
+	health := httpAdapter.NewHealthHandler([]httpAdapter.HealthCheck{
+		postgresCheck(db),
+		rabbitmqCheck(mqConn),
+	}, lgr)
+	root := http.NewServeMux()
+	health.Register(root)
+	root.Handle("/", handler)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      handler,
+		Handler:      root,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	lgr.Info("service_started", fmt.Sprintf("Order Service started on port %d", port), "startup", map[string]interface{}{
+	lgr.Info(ctx, "service_started", fmt.Sprintf("Order Service started on port %d", port), map[string]interface{}{
 		"port":           port,
 		"max_concurrent": maxConcurrent,
 	})
@@ -135,51 +369,75 @@ func runOrderService(ctx context.Context, db postgres.DB, mqConn rabbitmq.Connec
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
 
-		lgr.Info("shutdown_initiated", "Shutting down Order Service", "shutdown", nil)
+		lgr.Info(ctx, "shutdown_initiated", "Shutting down Order Service", nil)
+
+		stopBackground()
 
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		if err := server.Shutdown(shutdownCtx); err != nil {
-			lgr.Error("shutdown_error", "Error during shutdown", "shutdown", nil, err)
+			lgr.Error(ctx, "shutdown_error", "Error during shutdown", nil, err)
 		}
 	}()
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		lgr.Error("server_error", "Server error", "runtime", nil, err)
+		lgr.Error(ctx, "server_error", "Server error", nil, err)
 	}
 }
 
-func runKitchenWorker(ctx context.Context, db postgres.DB, mqConn rabbitmq.Connection, lgr logger.Logger, workerName, orderTypes string, heartbeatInterval, prefetch int) {
+func runKitchenWorker(ctx context.Context, cfg *config.Config, db postgres.DB, mqConn rabbitmq.Connection, lgr logger.Logger, workerName string, orderTypes []domain.OrderType, heartbeatInterval, prefetch, ovenSlots, adminPort int) {
 	// Initialize repositories
 	orderRepo := postgres.NewOrderRepository(db)
 	workerRepo := postgres.NewWorkerRepository(db)
 
 	// Initialize messaging
 	publisher := rabbitmq.NewPublisher(mqConn)
-	consumer := rabbitmq.NewConsumer(mqConn, prefetch)
+	consumer := rabbitmq.NewConsumer(mqConn, cfg.RabbitMQ, lgr, prefetch, ovenSlots, orderTypes)
 
 	// Initialize service
-	kitchenService := kitchen.NewService(orderRepo, workerRepo, publisher, lgr, workerName, orderTypes, heartbeatInterval)
+	kitchenService := kitchen.NewService(orderRepo, workerRepo, publisher, lgr, workerName, orderTypes, heartbeatInterval, ovenSlots)
 
 	// Initialize AMQP handler
-	orderHandlerAMQP := amqpAdapter.NewOrderHandler(kitchenService, lgr)
+	orderHandlerAMQP := amqpAdapter.NewOrderHandler(kitchenService, orderValidator(cfg), lgr)
 
 	// Start worker
 	if err := kitchenService.Start(ctx); err != nil {
 		log.Fatalf("Failed to start kitchen worker: %v", err)
 	}
 
-	lgr.Info("service_started", fmt.Sprintf("Kitchen Worker %s started", workerName), "startup", map[string]interface{}{
+	health := httpAdapter.NewHealthHandler([]httpAdapter.HealthCheck{
+		postgresCheck(db),
+		rabbitmqCheck(mqConn),
+		consumerCheck(consumer),
+		{Name: "heartbeat", Check: kitchenService.CheckHeartbeat},
+	}, lgr)
+	admin := startAdminServer(ctx, lgr, adminPort, health)
+	defer stopAdminServer(ctx, lgr, admin)
+
+	lgr.Info(ctx, "service_started", fmt.Sprintf("Kitchen Worker %s started", workerName), map[string]interface{}{
 		"worker_name": workerName,
 		"order_types": orderTypes,
 		"prefetch":    prefetch,
+		"oven_slots":  ovenSlots,
 	})
 
-	// Start consuming messages
+	// Start consuming messages; cancelling ordersCtx stops taking new orders without interrupting the current one
+	ordersCtx, stopOrders := context.WithCancel(ctx)
+	ordersDone := make(chan struct{})
 	go func() {
-		if err := consumer.ConsumeOrders(ctx, orderHandlerAMQP.HandleOrder); err != nil {
-			lgr.Error("consumer_error", "Error consuming orders", "runtime", nil, err)
+		defer close(ordersDone)
+		if err := consumer.ConsumeOrders(ordersCtx, orderHandlerAMQP.HandleOrder); err != nil && !errors.Is(err, context.Canceled) {
+			lgr.Error(ctx, "consumer_error", "Error consuming orders", nil, err)
+		}
+	}()
+
+	// Listen for cancellations to abort orders that are being cooked; keeps running while draining
+	notificationsCtx, stopNotifications := context.WithCancel(ctx)
+	defer stopNotifications()
+	go func() {
+		if err := consumer.ConsumeNotifications(notificationsCtx, orderHandlerAMQP.HandleStatusUpdate); err != nil && !errors.Is(err, context.Canceled) {
+			lgr.Error(ctx, "consumer_error", "Error consuming status updates", nil, err)
 		}
 	}()
 
@@ -188,14 +446,38 @@ func runKitchenWorker(ctx context.Context, db postgres.DB, mqConn rabbitmq.Conne
 	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 	<-sigint
 
-	lgr.Info("graceful_shutdown", "Shutting down Kitchen Worker", "shutdown", nil)
+	drainTimeout := time.Duration(cfg.Kitchen.DrainTimeoutSeconds) * time.Second
+	lgr.Info(ctx, "graceful_shutdown", "Shutting down Kitchen Worker", map[string]interface{}{
+		"drain_timeout_seconds": cfg.Kitchen.DrainTimeoutSeconds,
+	})
+
+	// 1. Stop taking new deliveries
+	stopOrders()
+
+	// 2. Let orders in progress finish; the rest are handed back to the queue at the deadline
+	drainCtx, cancelDrain := context.WithTimeout(ctx, drainTimeout)
+	if err := kitchenService.Drain(drainCtx); err != nil {
+		lgr.Error(ctx, "shutdown_error", "Error while draining orders", nil, err)
+	}
+	cancelDrain()
+
+	// 3. Wait until the consumer has acked or requeued its last delivery and closed the channel
+	select {
+	case <-ordersDone:
+	case <-time.After(5 * time.Second):
+		lgr.Error(ctx, "shutdown_error", "Orders consumer did not stop in time", nil, errors.New("consumer stop timeout"))
+	}
+	stopNotifications()
 
+	// 4. Mark the worker offline
 	if err := kitchenService.Shutdown(ctx); err != nil {
-		lgr.Error("shutdown_error", "Error during shutdown", "shutdown", nil, err)
+		lgr.Error(ctx, "shutdown_error", "Error during shutdown", nil, err)
 	}
+
+	lgr.Info(ctx, "shutdown_complete", "Kitchen Worker stopped", nil)
 }
 
-func runTrackingService(ctx context.Context, db postgres.DB, lgr logger.Logger, port int) {
+func runTrackingService(ctx context.Context, cfg *config.Config, db postgres.DB, lgr logger.Logger, port int) {
 	// Initialize repositories
 	orderRepo := postgres.NewOrderRepository(db)
 	workerRepo := postgres.NewWorkerRepository(db)
@@ -208,22 +490,41 @@ func runTrackingService(ctx context.Context, db postgres.DB, lgr logger.Logger,
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
-	mux.HandleFunc("/orders/", trackingHandler.HandleOrders)
-	mux.HandleFunc("/workers/status", trackingHandler.GetWorkersStatus)
+	// Customers may only poll the status; history and worker details expose staff names
+	auth := newAuthenticator(cfg)
+	mux.Handle("/orders", auth.RequireFunc(trackingHandler.ListOrders, domain.RoleCashier, domain.RoleKitchen))
+	mux.Handle("/orders/{number}/status", auth.RequireFunc(trackingHandler.HandleOrders, domain.RoleCustomer, domain.RoleCashier, domain.RoleKitchen))
+	mux.Handle("/orders/", auth.RequireFunc(trackingHandler.HandleOrders, domain.RoleCashier, domain.RoleKitchen))
+	mux.Handle("/workers/status", auth.RequireFunc(trackingHandler.GetWorkersStatus, domain.RoleAdmin))
+	mux.Handle("/metrics", metrics.Default.Handler())
 
 	// Apply middleware
-	handler := httpAdapter.LoggingMiddleware(lgr)(mux)
+	handler := rateLimited(cfg, lgr, mux)
+	handler = httpAdapter.AuthMiddleware(auth, lgr)(handler)
+	handler = authFailuresLimited(cfg, lgr, handler)
+	handler = httpAdapter.LoggingMiddleware(lgr)(handler)
 	handler = httpAdapter.RecoveryMiddleware(lgr)(handler)
+	handler = httpAdapter.MetricsMiddleware(mux)(handler)
+	handler = httpAdapter.TracingMiddleware(tracing.FromContext(ctx))(handler)
+	handler = httpAdapter.RequestIDMiddleware(handler)
+
+	// Health probes bypass auth and rate limiting
+	health := httpAdapter.NewHealthHandler([]httpAdapter.HealthCheck{
+		postgresCheck(db),
+	}, lgr)
+	root := http.NewServeMux()
+	health.Register(root)
+	root.Handle("/", handler)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      handler,
+		Handler:      root,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	lgr.Info("service_started", fmt.Sprintf("Tracking Service started on port %d", port), "startup", map[string]interface{}{
+	lgr.Info(ctx, "service_started", fmt.Sprintf("Tracking Service started on port %d", port), map[string]interface{}{
 		"port": port,
 	})
 
@@ -233,34 +534,41 @@ func runTrackingService(ctx context.Context, db postgres.DB, lgr logger.Logger,
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
 
-		lgr.Info("shutdown_initiated", "Shutting down Tracking Service", "shutdown", nil)
+		lgr.Info(ctx, "shutdown_initiated", "Shutting down Tracking Service", nil)
 
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		if err := server.Shutdown(shutdownCtx); err != nil {
-			lgr.Error("shutdown_error", "Error during shutdown", "shutdown", nil, err)
+			lgr.Error(ctx, "shutdown_error", "Error during shutdown", nil, err)
 		}
 	}()
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		lgr.Error("server_error", "Server error", "runtime", nil, err)
+		lgr.Error(ctx, "server_error", "Server error", nil, err)
 	}
 }
 
-func runNotificationSubscriber(ctx context.Context, mqConn rabbitmq.Connection, lgr logger.Logger) {
+func runNotificationSubscriber(ctx context.Context, cfg *config.Config, mqConn rabbitmq.Connection, lgr logger.Logger, adminPort int) {
 	// Initialize consumer
-	consumer := rabbitmq.NewConsumer(mqConn, 1)
+	consumer := rabbitmq.NewConsumer(mqConn, cfg.RabbitMQ, lgr, 1, 1, nil)
 
 	// Initialize handler
 	notificationHandler := amqpAdapter.NewNotificationHandler(lgr)
 
-	lgr.Info("service_started", "Notification Subscriber started", "startup", nil)
+	lgr.Info(ctx, "service_started", "Notification Subscriber started", nil)
+
+	health := httpAdapter.NewHealthHandler([]httpAdapter.HealthCheck{
+		rabbitmqCheck(mqConn),
+		consumerCheck(consumer),
+	}, lgr)
+	admin := startAdminServer(ctx, lgr, adminPort, health)
+	defer stopAdminServer(ctx, lgr, admin)
 
 	// Start consuming notifications
 	go func() {
 		if err := consumer.ConsumeNotifications(ctx, notificationHandler.HandleNotification); err != nil {
-			lgr.Error("consumer_error", "Error consuming notifications", "runtime", nil, err)
+			lgr.Error(ctx, "consumer_error", "Error consuming notifications", nil, err)
 		}
 	}()
 
@@ -269,5 +577,88 @@ func runNotificationSubscriber(ctx context.Context, mqConn rabbitmq.Connection,
 	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 	<-sigint
 
-	lgr.Info("shutdown_initiated", "Shutting down Notification Subscriber", "shutdown", nil)
+	lgr.Info(ctx, "shutdown_initiated", "Shutting down Notification Subscriber", nil)
+}
+
+// runKitchenQueueMigration recreates kitchen queues with the configured priority levels and drains the shared
+// kitchen_queue into the per-type queues, keeping queued orders. It runs once with kitchen workers stopped.
+func runKitchenQueueMigration(ctx context.Context, cfg *config.Config, mqConn rabbitmq.Connection, lgr logger.Logger) {
+	moved, err := rabbitmq.MigrateKitchenQueues(ctx, mqConn, cfg.RabbitMQ.MaxPriority)
+	if err != nil {
+		lgr.Error(ctx, "migration_failed", "Kitchen queues migration failed", map[string]interface{}{"moved": moved}, err)
+		os.Exit(1)
+	}
+
+	lgr.Info(ctx, "migration_completed", "Kitchen queues match the configured topology", map[string]interface{}{
+		"max_priority": cfg.RabbitMQ.MaxPriority,
+		"moved":        moved,
+	})
+}
+
+// startAdminServer serves /metrics and health probes on a side port for modes without an HTTP API; port 0 disables it
+func startAdminServer(ctx context.Context, lgr logger.Logger, port int, health *httpAdapter.HealthHandler) *http.Server {
+	if port == 0 {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+	health.Register(mux)
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", port),
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			lgr.Error(ctx, "server_error", "Admin server error", map[string]interface{}{"port": port}, err)
+		}
+	}()
+
+	lgr.Info(ctx, "admin_server_started", fmt.Sprintf("Admin server started on port %d", port), map[string]interface{}{
+		"port": port,
+	})
+
+	return server
+}
+
+func stopAdminServer(ctx context.Context, lgr logger.Logger, server *http.Server) {
+	if server == nil {
+		return
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		lgr.Error(ctx, "shutdown_error", "Error during admin server shutdown", nil, err)
+	}
+}
+
+// postgresCheck pings the database for /readyz
+func postgresCheck(db postgres.DB) httpAdapter.HealthCheck {
+	return httpAdapter.HealthCheck{Name: "postgres", Check: db.Ping}
+}
+
+// rabbitmqCheck reports a lost broker connection for /readyz
+func rabbitmqCheck(conn rabbitmq.Connection) httpAdapter.HealthCheck {
+	return httpAdapter.HealthCheck{Name: "rabbitmq", Check: func(context.Context) error {
+		if conn.IsClosed() {
+			return errors.New("connection is closed")
+		}
+		return nil
+	}}
+}
+
+// consumerCheck reports consume loops that are not subscribed, e.g. while reconnecting after a lost channel
+func consumerCheck(consumer interfaces.MessageConsumer) httpAdapter.HealthCheck {
+	return httpAdapter.HealthCheck{Name: "consumer", Check: func(context.Context) error {
+		if !consumer.Consuming() {
+			return errors.New("consumer is not subscribed")
+		}
+		return nil
+	}}
 }
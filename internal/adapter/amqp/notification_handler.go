@@ -22,15 +22,14 @@ func NewNotificationHandler(logger logger.Logger) *NotificationHandler {
 func (h *NotificationHandler) HandleNotification(ctx context.Context, body []byte) error {
 	var msg interfaces.StatusUpdateMessage
 	if err := json.Unmarshal(body, &msg); err != nil {
-		h.logger.Error("message_parse_failed", "Failed to parse notification", "", nil, err)
+		h.logger.Error(ctx, "message_parse_failed", "Failed to parse notification", nil, err)
 		return err
 	}
 
-	h.logger.Debug("notification_received", fmt.Sprintf("Received status update for order %s", msg.OrderNumber),
-		msg.OrderNumber, map[string]interface{}{
-			"order_number": msg.OrderNumber,
-			"new_status":   msg.NewStatus,
-		})
+	h.logger.Debug(ctx, "notification_received", fmt.Sprintf("Received status update for order %s", msg.OrderNumber), map[string]interface{}{
+		"order_number": msg.OrderNumber,
+		"new_status":   msg.NewStatus,
+	})
 
 	// Print to console
 	fmt.Printf("Notification for order %s: Status changed from '%s' to '%s' by %s\n",
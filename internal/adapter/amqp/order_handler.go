@@ -3,29 +3,62 @@ package amqp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 )
 
 type OrderHandler struct {
-	service interfaces.KitchenService
-	logger  logger.Logger
+	service   interfaces.KitchenService
+	validator interfaces.OrderValidator
+	logger    logger.Logger
 }
 
-func NewOrderHandler(service interfaces.KitchenService, logger logger.Logger) *OrderHandler {
+func NewOrderHandler(service interfaces.KitchenService, validator interfaces.OrderValidator, logger logger.Logger) *OrderHandler {
 	return &OrderHandler{
-		service: service,
-		logger:  logger,
+		service:   service,
+		validator: validator,
+		logger:    logger,
 	}
 }
 
 func (h *OrderHandler) HandleOrder(ctx context.Context, body []byte) error {
 	var msg interfaces.OrderMessage
 	if err := json.Unmarshal(body, &msg); err != nil {
-		h.logger.Error("message_parse_failed", "Failed to parse order message", "", nil, err)
-		return err
+		h.logger.Error(ctx, "message_parse_failed", "Failed to parse order message", nil, err)
+		return fmt.Errorf("%w: %w", domain.ErrMalformedOrderMessage, err)
+	}
+
+	// Некорректный заказ не должен попасть на кухню: повтор не поможет, сообщение сразу уйдет в DLQ
+	if err := h.validator.ValidateOrderMessage(msg); err != nil {
+		h.logger.Error(ctx, "validation_failed", "Order message validation failed", map[string]interface{}{
+			"order_number": msg.OrderNumber,
+		}, err)
+		return fmt.Errorf("%w: %w", domain.ErrMalformedOrderMessage, err)
 	}
 
 	return h.service.ProcessOrder(ctx, msg)
 }
+
+// HandleStatusUpdate прерывает готовку заказа, который отменили во время приготовления
+func (h *OrderHandler) HandleStatusUpdate(ctx context.Context, body []byte) error {
+	var msg interfaces.StatusUpdateMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		h.logger.Error(ctx, "message_parse_failed", "Failed to parse status update", nil, err)
+		return err
+	}
+
+	if msg.NewStatus != domain.StatusCancelled {
+		return nil
+	}
+
+	if h.service.AbortOrder(msg.OrderNumber) {
+		h.logger.Debug(ctx, "order_abort_requested", "Cancellation received for order in progress", map[string]interface{}{
+			"order_number": msg.OrderNumber,
+		})
+	}
+
+	return nil
+}
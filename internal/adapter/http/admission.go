@@ -0,0 +1,56 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+// ConcurrencyLimitMiddleware ограничивает число одновременно обрабатываемых запросов.
+// Запросы сверх лимита не ждут в очереди, а сразу получают 503 с Retry-After.
+func ConcurrencyLimitMiddleware(limit int, retryAfter time.Duration, logger logger.Logger) func(http.Handler) http.Handler {
+	slots := make(chan struct{}, limit)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				next.ServeHTTP(w, r)
+			default:
+				logger.Debug(r.Context(), "request_rejected", "Too many concurrent requests", map[string]interface{}{
+					"path":  r.URL.Path,
+					"limit": limit,
+				})
+				rejectRequest(w, http.StatusServiceUnavailable, retryAfter, "Server is busy, retry later")
+			}
+		})
+	}
+}
+
+// KitchenBacklogGuard отклоняет создание заказов (POST), пока очередь кухни переполнена
+func KitchenBacklogGuard(backlog interfaces.KitchenBacklog, retryAfter time.Duration, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && backlog.Saturated() {
+				logger.Debug(r.Context(), "order_rejected", "Kitchen is saturated", map[string]interface{}{
+					"queue_depth": backlog.Depth(),
+				})
+				rejectRequest(w, http.StatusTooManyRequests, retryAfter, "Kitchen is at capacity, retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func rejectRequest(w http.ResponseWriter, statusCode int, retryAfter time.Duration, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+)
+
+type principalKey struct{}
+
+// PrincipalFromContext возвращает клиента, аутентифицированного AuthMiddleware
+func PrincipalFromContext(ctx context.Context) (*domain.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*domain.Principal)
+	return p, ok
+}
+
+type apiKey struct {
+	key       []byte
+	principal *domain.Principal
+}
+
+// Authenticator проверяет статические API-ключи (X-API-Key) и HS256 JWT (Authorization: Bearer)
+type Authenticator struct {
+	enabled   bool
+	apiKeys   []apiKey
+	jwtSecret []byte
+	jwtIssuer string
+	now       func() time.Time
+}
+
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:   cfg.Enabled,
+		jwtSecret: []byte(cfg.JWTSecret),
+		jwtIssuer: cfg.JWTIssuer,
+		now:       time.Now,
+	}
+
+	keys, err := cfg.Keys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		roles, err := parseRoles(k.Roles)
+		if err != nil {
+			return nil, fmt.Errorf("api key %s: %w", k.Name, err)
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{
+			key:       []byte(k.Key),
+			principal: &domain.Principal{Subject: k.Name, Roles: roles},
+		})
+	}
+
+	if a.enabled && len(a.apiKeys) == 0 && len(a.jwtSecret) == 0 {
+		return nil, errors.New("auth is enabled but neither api_keys nor jwt_secret is configured")
+	}
+
+	return a, nil
+}
+
+// authenticate возвращает nil без ошибки, если учетные данные не переданы
+func (a *Authenticator) authenticate(r *http.Request) (*domain.Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		for _, k := range a.apiKeys {
+			if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+				return k.principal, nil
+			}
+		}
+		return nil, domain.ErrInvalidToken
+	}
+
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || len(a.jwtSecret) == 0 {
+			return nil, domain.ErrInvalidToken
+		}
+		return a.verifyJWT(strings.TrimSpace(token))
+	}
+
+	return nil, nil
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Roles     []string `json:"roles"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+// verifyJWT проверяет компактный JWT с подписью HS256; exp обязателен
+func (a *Authenticator) verifyJWT(token string) (*domain.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, domain.ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	now := a.now().Unix()
+	if claims.ExpiresAt == nil || now >= *claims.ExpiresAt {
+		return nil, domain.ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now < *claims.NotBefore {
+		return nil, domain.ErrInvalidToken
+	}
+	if a.jwtIssuer != "" && claims.Issuer != a.jwtIssuer {
+		return nil, domain.ErrInvalidToken
+	}
+	if claims.Subject == "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	roles, err := parseRoles(claims.Roles)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return &domain.Principal{Subject: claims.Subject, Roles: roles}, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func parseRoles(names []string) ([]domain.Role, error) {
+	roles := make([]domain.Role, 0, len(names))
+	for _, name := range names {
+		role, err := domain.ParseRole(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// AuthMiddleware кладет клиента в контекст запроса. Запросы без учетных данных проходят дальше
+// анонимно, доступ к маршрутам проверяет Require; неверный ключ или токен сразу получает 401.
+func AuthMiddleware(auth *Authenticator, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auth.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := auth.authenticate(r)
+			if err != nil {
+				logger.Debug(r.Context(), "auth_failed", "Authentication failed", map[string]interface{}{
+					"path":   r.URL.Path,
+					"reason": err.Error(),
+				})
+				rejectUnauthorized(w, err.Error())
+				return
+			}
+			if principal != nil {
+				r = r.WithContext(context.WithValue(r.Context(), principalKey{}, principal))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Require пропускает только клиентов с одной из ролей (admin допускается всегда)
+func (a *Authenticator) Require(roles ...domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !a.enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				rejectUnauthorized(w, domain.ErrUnauthenticated.Error())
+				return
+			}
+			if !principal.HasAnyRole(roles...) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "Forbidden"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireFunc - Require для http.HandlerFunc
+func (a *Authenticator) RequireFunc(handler http.HandlerFunc, roles ...domain.Role) http.Handler {
+	return a.Require(roles...)(handler)
+}
+
+func rejectUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="pizzas"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck - проверка одной зависимости для /readyz; nil означает, что зависимость доступна
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// HealthHandler отвечает супервизору: /healthz - процесс жив, /readyz - зависимости доступны
+type HealthHandler struct {
+	checks []HealthCheck
+	logger logger.Logger
+}
+
+func NewHealthHandler(checks []HealthCheck, logger logger.Logger) *HealthHandler {
+	return &HealthHandler{
+		checks: checks,
+		logger: logger,
+	}
+}
+
+// Healthz не трогает зависимости: если процесс ответил, он жив
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz выполняет все проверки и возвращает 503, если хотя бы одна не прошла
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	resp := HealthResponse{Status: "ready", Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+
+	for _, check := range h.checks {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		err := check.Check(ctx)
+		cancel()
+
+		if err != nil {
+			resp.Checks[check.Name] = err.Error()
+			resp.Status = "not_ready"
+			status = http.StatusServiceUnavailable
+			h.logger.Debug(r.Context(), "readiness_check_failed", "Readiness check failed", map[string]interface{}{
+				"check": check.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		resp.Checks[check.Name] = "ok"
+	}
+
+	writeHealth(w, status, resp)
+}
+
+// Register вешает /healthz и /readyz на mux
+func (h *HealthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.Healthz)
+	mux.HandleFunc("GET /readyz", h.Readyz)
+}
+
+func writeHealth(w http.ResponseWriter, status int, resp HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,282 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+type MenuHandler struct {
+	service interfaces.MenuService
+	logger  logger.Logger
+}
+
+func NewMenuHandler(service interfaces.MenuService, logger logger.Logger) *MenuHandler {
+	return &MenuHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type MenuItemRequest struct {
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Category       string                 `json:"category"`
+	Price          domain.Money           `json:"price"`
+	Available      *bool                  `json:"available,omitempty"`
+	ModifierGroups []ModifierGroupRequest `json:"modifier_groups,omitempty"`
+}
+
+// ModifierGroupRequest: id из ответа GET сохраняет группу и id ее модификаторов,
+// группы и модификаторы, не указанные в PUT, удаляются
+type ModifierGroupRequest struct {
+	ID        int               `json:"id,omitempty"`
+	Name      string            `json:"name"`
+	MinSelect int               `json:"min_select"`
+	MaxSelect int               `json:"max_select"`
+	Modifiers []ModifierRequest `json:"modifiers"`
+}
+
+type ModifierRequest struct {
+	ID        int          `json:"id,omitempty"`
+	Name      string       `json:"name"`
+	Price     domain.Money `json:"price"`
+	Available *bool        `json:"available,omitempty"`
+}
+
+type MenuItemResponse struct {
+	ID             int                     `json:"id"`
+	Name           string                  `json:"name"`
+	Description    string                  `json:"description"`
+	Category       string                  `json:"category"`
+	Price          domain.Money            `json:"price"`
+	Available      bool                    `json:"available"`
+	ModifierGroups []ModifierGroupResponse `json:"modifier_groups"`
+}
+
+type ModifierGroupResponse struct {
+	ID        int                `json:"id"`
+	Name      string             `json:"name"`
+	MinSelect int                `json:"min_select"`
+	MaxSelect int                `json:"max_select"`
+	Modifiers []ModifierResponse `json:"modifiers"`
+}
+
+type ModifierResponse struct {
+	ID        int          `json:"id"`
+	Name      string       `json:"name"`
+	Price     domain.Money `json:"price"`
+	Available bool         `json:"available"`
+}
+
+// GetMenu обрабатывает GET /menu: только доступные позиции
+func (h *MenuHandler) GetMenu(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	items, err := h.service.ListMenu(r.Context(), false)
+	if err != nil {
+		h.respondServiceError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, toMenuItemResponses(items))
+}
+
+// HandleAdminMenu обрабатывает /admin/menu: список всех позиций и создание новой
+func (h *MenuHandler) HandleAdminMenu(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		items, err := h.service.ListMenu(r.Context(), true)
+		if err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		h.respondJSON(w, http.StatusOK, toMenuItemResponses(items))
+
+	case http.MethodPost:
+		cmd, ok := h.decodeMenuItem(w, r)
+		if !ok {
+			return
+		}
+		item, err := h.service.CreateItem(r.Context(), cmd)
+		if err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		h.respondJSON(w, http.StatusCreated, toMenuItemResponse(item))
+
+	default:
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// HandleAdminMenuItem обрабатывает /admin/menu/{id}
+func (h *MenuHandler) HandleAdminMenuItem(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 {
+		h.respondError(w, "Not found", http.StatusNotFound, nil)
+		return
+	}
+
+	id, err := strconv.Atoi(parts[2])
+	if err != nil || id < 1 {
+		h.respondError(w, "Not found", http.StatusNotFound, nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		item, err := h.service.GetItem(r.Context(), id)
+		if err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		h.respondJSON(w, http.StatusOK, toMenuItemResponse(item))
+
+	case http.MethodPut:
+		cmd, ok := h.decodeMenuItem(w, r)
+		if !ok {
+			return
+		}
+		item, err := h.service.UpdateItem(r.Context(), id, cmd)
+		if err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		h.respondJSON(w, http.StatusOK, toMenuItemResponse(item))
+
+	case http.MethodDelete:
+		if err := h.service.DeleteItem(r.Context(), id); err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+	}
+}
+
+func (h *MenuHandler) decodeMenuItem(w http.ResponseWriter, r *http.Request) (interfaces.MenuItemCommand, bool) {
+	var req MenuItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, "Invalid request body", http.StatusBadRequest, nil)
+		return interfaces.MenuItemCommand{}, false
+	}
+
+	// Без modifier_groups в запросе группы позиции не меняются
+	var groups []interfaces.ModifierGroupCommand
+	if req.ModifierGroups != nil {
+		groups = make([]interfaces.ModifierGroupCommand, len(req.ModifierGroups))
+	}
+	for i, g := range req.ModifierGroups {
+		groups[i] = interfaces.ModifierGroupCommand{
+			ID:        g.ID,
+			Name:      strings.TrimSpace(g.Name),
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			Modifiers: make([]interfaces.ModifierCommand, len(g.Modifiers)),
+		}
+		for j, m := range g.Modifiers {
+			groups[i].Modifiers[j] = interfaces.ModifierCommand{
+				ID:        m.ID,
+				Name:      strings.TrimSpace(m.Name),
+				Price:     m.Price,
+				Available: availableOrDefault(m.Available),
+			}
+		}
+	}
+
+	return interfaces.MenuItemCommand{
+		Name:           strings.TrimSpace(req.Name),
+		Description:    strings.TrimSpace(req.Description),
+		Category:       strings.TrimSpace(req.Category),
+		Price:          req.Price,
+		Available:      availableOrDefault(req.Available),
+		ModifierGroups: groups,
+	}, true
+}
+
+// availableOrDefault: новые позиции и модификаторы по умолчанию доступны для заказа
+func availableOrDefault(available *bool) bool {
+	if available == nil {
+		return true
+	}
+	return *available
+}
+
+func toMenuItemResponse(item *domain.MenuItem) MenuItemResponse {
+	groups := make([]ModifierGroupResponse, len(item.ModifierGroups))
+	for i, g := range item.ModifierGroups {
+		groups[i] = ModifierGroupResponse{
+			ID:        g.ID,
+			Name:      g.Name,
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			Modifiers: make([]ModifierResponse, len(g.Modifiers)),
+		}
+		for j, m := range g.Modifiers {
+			groups[i].Modifiers[j] = ModifierResponse{
+				ID:        m.ID,
+				Name:      m.Name,
+				Price:     m.Price,
+				Available: m.Available,
+			}
+		}
+	}
+
+	return MenuItemResponse{
+		ID:             item.ID,
+		Name:           item.Name,
+		Description:    item.Description,
+		Category:       item.Category,
+		Price:          item.Price,
+		Available:      item.Available,
+		ModifierGroups: groups,
+	}
+}
+
+func toMenuItemResponses(items []*domain.MenuItem) []MenuItemResponse {
+	resp := make([]MenuItemResponse, len(items))
+	for i, item := range items {
+		resp[i] = toMenuItemResponse(item)
+	}
+	return resp
+}
+
+func (h *MenuHandler) respondServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrors domain.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		h.respondError(w, "Validation failed", http.StatusBadRequest, toValidationErrors(validationErrors))
+	case errors.Is(err, domain.ErrMenuItemNotFound):
+		h.respondError(w, "Menu item not found", http.StatusNotFound, nil)
+	case errors.Is(err, domain.ErrMenuItemInUse):
+		h.respondError(w, "Menu item is used by a promotion; mark it unavailable instead", http.StatusConflict, nil)
+	default:
+		h.logger.Error(r.Context(), "menu_request_failed", "Menu request failed", nil, err)
+		h.respondError(w, "Internal server error", http.StatusInternalServerError, nil)
+	}
+}
+
+func (h *MenuHandler) respondJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (h *MenuHandler) respondError(w http.ResponseWriter, message string, statusCode int, validationErrors []ValidationError) {
+	h.respondJSON(w, statusCode, ErrorResponse{
+		Error:  message,
+		Errors: validationErrors,
+	})
+}
@@ -3,18 +3,122 @@ package http
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
 )
 
+// RequestIDMiddleware принимает X-Request-ID клиента или генерирует новый, возвращает его
+// в ответе и кладет в контекст запроса, откуда его берут логгер и публикация в RabbitMQ
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(logger.RequestIDHeader)
+		if !logger.ValidRequestID(requestID) {
+			requestID = logger.NewRequestID()
+		}
+
+		w.Header().Set(logger.RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// statusRecorder запоминает код ответа для трейсинга и метрик
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// TracingMiddleware открывает серверный спан на каждый запрос. Родитель берется из заголовка
+// traceparent, если клиент его передал; трейсер кладется в контекст для спанов сервисов и БД.
+func TracingMiddleware(tracer *tracing.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if tracer == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.WithTracer(r.Context(), tracer)
+			if parent, ok := tracing.ParseTraceparent(r.Header.Get(tracing.TraceparentHeader)); ok {
+				ctx = tracing.WithRemoteParent(ctx, parent)
+			}
+
+			ctx, span := tracing.Start(ctx, fmt.Sprintf("HTTP %s %s", r.Method, r.URL.Path), tracing.KindServer)
+			defer span.End()
+			span.SetAttr("http.method", r.Method)
+			span.SetAttr("http.path", r.URL.Path)
+			span.SetAttr("request_id", logger.RequestID(ctx))
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			span.SetAttr("http.status_code", rec.status)
+			if rec.status >= http.StatusInternalServerError {
+				span.SetError(fmt.Errorf("HTTP %d", rec.status))
+			}
+		})
+	}
+}
+
+// MetricsMiddleware считает запросы и их длительность по шаблону маршрута из routes,
+// а не по фактическому пути, чтобы номера заказов не порождали новые серии
+func MetricsMiddleware(routes *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			route := "unmatched"
+			if _, pattern := routes.Handler(r); pattern != "" {
+				route = pattern
+			}
+			method := metricMethod(r.Method)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			metrics.HTTPRequests.Inc(route, method, strconv.Itoa(rec.status))
+			metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), route, method)
+		})
+	}
+}
+
+// metricMethod ограничивает метку method стандартными методами
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
 func LoggingMiddleware(logger logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			requestID := fmt.Sprintf("req-%d", time.Now().UnixNano())
 
-			logger.Debug("http_request", fmt.Sprintf("%s %s", r.Method, r.URL.Path), requestID, map[string]interface{}{
+			logger.Debug(r.Context(), "http_request", fmt.Sprintf("%s %s", r.Method, r.URL.Path), map[string]interface{}{
 				"method": r.Method,
 				"path":   r.URL.Path,
 			})
@@ -22,7 +126,7 @@ func LoggingMiddleware(logger logger.Logger) func(http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 
 			duration := time.Since(start)
-			logger.Debug("http_response", "Request completed", requestID, map[string]interface{}{
+			logger.Debug(r.Context(), "http_response", "Request completed", map[string]interface{}{
 				"duration_ms": duration.Milliseconds(),
 			})
 		})
@@ -34,8 +138,7 @@ func RecoveryMiddleware(logger logger.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					requestID := fmt.Sprintf("req-%d", time.Now().UnixNano())
-					logger.Error("panic_recovered", "Panic recovered", requestID, nil, fmt.Errorf("%v", err))
+					logger.Error(r.Context(), "panic_recovered", "Panic recovered", nil, fmt.Errorf("%v", err))
 					http.Error(w, "Internal server error", http.StatusInternalServerError)
 				}
 			}()
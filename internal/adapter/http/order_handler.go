@@ -1,46 +1,98 @@
 package http
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 )
 
 type OrderHandler struct {
-	service interfaces.OrderService
-	logger  logger.Logger
+	service     interfaces.OrderService
+	idempotency interfaces.IdempotencyService
+	validator   interfaces.OrderValidator
+	logger      logger.Logger
 }
 
-func NewOrderHandler(service interfaces.OrderService, logger logger.Logger) *OrderHandler {
+func NewOrderHandler(service interfaces.OrderService, idempotency interfaces.IdempotencyService, validator interfaces.OrderValidator, logger logger.Logger) *OrderHandler {
 	return &OrderHandler{
-		service: service,
-		logger:  logger,
+		service:     service,
+		idempotency: idempotency,
+		validator:   validator,
+		logger:      logger,
 	}
 }
 
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	maxRequestBodySize   = 1 << 20
+)
+
 type CreateOrderRequest struct {
 	CustomerName    string             `json:"customer_name"`
 	OrderType       string             `json:"order_type"`
 	TableNumber     *int               `json:"table_number,omitempty"`
 	DeliveryAddress *string            `json:"delivery_address,omitempty"`
 	Items           []OrderItemRequest `json:"items"`
+	PromoCode       string             `json:"promo_code,omitempty"`
 }
 
 type OrderItemRequest struct {
-	Name     string  `json:"name"`
-	Quantity int     `json:"quantity"`
-	Price    float64 `json:"price"`
+	MenuItemID  int   `json:"menu_item_id"`
+	Quantity    int   `json:"quantity"`
+	ModifierIDs []int `json:"modifier_ids,omitempty"`
 }
 
 type CreateOrderResponse struct {
-	OrderNumber string  `json:"order_number"`
-	Status      string  `json:"status"`
-	TotalAmount float64 `json:"total_amount"`
+	OrderNumber string          `json:"order_number"`
+	Status      string          `json:"status"`
+	TotalAmount domain.Money    `json:"total_amount"`
+	Currency    string          `json:"currency"`
+	Pricing     PricingResponse `json:"pricing"`
+}
+
+type PricingResponse struct {
+	Subtotal      domain.Money      `json:"subtotal"`
+	Discount      domain.Money      `json:"discount"`
+	PromoCode     string            `json:"promo_code,omitempty"`
+	ServiceCharge domain.Money      `json:"service_charge"`
+	DeliveryFee   domain.Money      `json:"delivery_fee"`
+	Taxes         []TaxLineResponse `json:"taxes"`
+	Total         domain.Money      `json:"total"`
+}
+
+type TaxLineResponse struct {
+	Name    string       `json:"name"`
+	RateBps int64        `json:"rate_bps"`
+	Amount  domain.Money `json:"amount"`
+}
+
+// CancelOrderRequest: автор отмены берется из аутентификации, cancelled_by - только пометка в журнале
+type CancelOrderRequest struct {
+	Reason      string `json:"reason"`
+	CancelledBy string `json:"cancelled_by,omitempty"`
+}
+
+// CompleteOrderRequest: автор выдачи берется из аутентификации, completed_by - только пометка в журнале
+type CompleteOrderRequest struct {
+	CompletedBy string `json:"completed_by,omitempty"`
+}
+
+type OrderStatusResponse struct {
+	OrderNumber  string     `json:"order_number"`
+	Status       string     `json:"status"`
+	Handoff      string     `json:"handoff,omitempty"`
+	HandedOverAt *time.Time `json:"handed_over_at,omitempty"`
 }
 
 type ValidationError struct {
@@ -53,29 +105,21 @@ type ErrorResponse struct {
 	Errors []ValidationError `json:"errors,omitempty"`
 }
 
-// Регулярное выражение для валидации имени клиента
-// Разрешены: буквы, пробелы, дефисы, апострофы
-var customerNameRegex = regexp.MustCompile(`^[a-zA-Z\s\-']+$`)
-
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
 		return
 	}
 
-	var req CreateOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
 		h.respondError(w, "Invalid request body", http.StatusBadRequest, nil)
 		return
 	}
 
-	// Валидация входных данных
-	if validationErrors := validateCreateOrderRequest(req); len(validationErrors) > 0 {
-		h.logger.Error("validation_failed", "Order validation failed", "", map[string]interface{}{
-			"errors": validationErrors,
-		}, fmt.Errorf("validation failed"))
-
-		h.respondError(w, "Validation failed", http.StatusBadRequest, validationErrors)
+	var req CreateOrderRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.respondError(w, "Invalid request body", http.StatusBadRequest, nil)
 		return
 	}
 
@@ -85,197 +129,314 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		TableNumber:     req.TableNumber,
 		DeliveryAddress: req.DeliveryAddress,
 		Items:           convertItemsToCommand(req.Items),
+		PromoCode:       strings.TrimSpace(req.PromoCode),
+		CustomerSubject: customerSubject(r),
+	}
+
+	// Валидация входных данных до резервирования Idempotency-Key
+	if err := h.validator.ValidateCreateOrder(cmd); err != nil {
+		var validationErrors domain.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			h.logger.Error(r.Context(), "validation_failed", "Order validation failed", map[string]interface{}{
+				"errors": toValidationErrors(validationErrors),
+			}, err)
+			h.respondError(w, "Validation failed", http.StatusBadRequest, toValidationErrors(validationErrors))
+			return
+		}
+		h.respondError(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	// Idempotency-Key: повтор запроса возвращает исходный ответ вместо нового заказа
+	idempotencyKey := strings.TrimSpace(r.Header.Get(idempotencyKeyHeader))
+	var idempotencyToken string
+	if idempotencyKey != "" {
+		if len(idempotencyKey) > maxIdempotencyKeyLen {
+			h.respondError(w, "Validation failed", http.StatusBadRequest, []ValidationError{{
+				Field:   idempotencyKeyHeader,
+				Message: fmt.Sprintf("idempotency key must not exceed %d characters", maxIdempotencyKeyLen),
+			}})
+			return
+		}
+
+		record, token, err := h.idempotency.Begin(r.Context(), principalSubject(r), idempotencyKey, hashRequest(req))
+		switch {
+		case errors.Is(err, domain.ErrIdempotencyKeyMismatch):
+			h.respondError(w, err.Error(), http.StatusUnprocessableEntity, nil)
+			return
+		case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+			h.respondError(w, err.Error(), http.StatusConflict, nil)
+			return
+		case err != nil:
+			h.logger.Error(r.Context(), "idempotency_failed", "Failed to check idempotency key", nil, err)
+			h.respondError(w, "Internal server error", http.StatusInternalServerError, nil)
+			return
+		case record != nil:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(record.ResponseCode)
+			w.Write(record.ResponseBody)
+			return
+		}
+		idempotencyToken = token
 	}
 
 	result, err := h.service.CreateOrder(r.Context(), cmd)
 	if err != nil {
-		h.logger.Error("order_creation_failed", "Failed to create order", "", nil, err)
+		h.logger.Error(r.Context(), "order_creation_failed", "Failed to create order", nil, err)
+		h.releaseIdempotencyKey(r, idempotencyKey, idempotencyToken)
+
+		var validationErrors domain.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			h.respondError(w, "Validation failed", http.StatusBadRequest, toValidationErrors(validationErrors))
+			return
+		}
+
 		h.respondError(w, err.Error(), http.StatusBadRequest, nil)
 		return
 	}
 
-	resp := CreateOrderResponse{
+	resp, err := json.Marshal(CreateOrderResponse{
 		OrderNumber: result.Number,
 		Status:      string(result.Status),
 		TotalAmount: result.TotalAmount,
+		Currency:    result.Currency,
+		Pricing:     toPricingResponse(result.Pricing),
+	})
+	if err != nil {
+		h.releaseIdempotencyKey(r, idempotencyKey, idempotencyToken)
+		h.respondError(w, "Internal server error", http.StatusInternalServerError, nil)
+		return
+	}
+
+	if idempotencyKey != "" {
+		if err := h.idempotency.Complete(r.Context(), principalSubject(r), idempotencyKey, idempotencyToken, http.StatusCreated, resp); err != nil {
+			// Заказ уже создан, поэтому отвечаем клиенту, но повтор с этим ключом вернет 409
+			h.logger.Error(r.Context(), "idempotency_failed", "Failed to store idempotent response", map[string]interface{}{
+				"order_number": result.Number,
+			}, err)
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(resp)
+	w.Write(resp)
 }
 
-func validateCreateOrderRequest(req CreateOrderRequest) []ValidationError {
-	var errors []ValidationError
-
-	// 1. Валидация customer_name
-	customerName := strings.TrimSpace(req.CustomerName)
-	if len(customerName) < 1 {
-		errors = append(errors, ValidationError{
-			Field:   "customer_name",
-			Message: "customer name is required",
-		})
-	} else if len(customerName) > 100 {
-		errors = append(errors, ValidationError{
-			Field:   "customer_name",
-			Message: "customer name must not exceed 100 characters",
-		})
-	} else if !customerNameRegex.MatchString(customerName) {
-		errors = append(errors, ValidationError{
-			Field:   "customer_name",
-			Message: "customer name must contain only letters, spaces, hyphens, and apostrophes",
-		})
-	}
-
-	// 2. Валидация order_type
-	validOrderTypes := map[string]bool{
-		"dine_in":  true,
-		"takeout":  true,
-		"delivery": true,
-	}
-
-	if !validOrderTypes[req.OrderType] {
-		errors = append(errors, ValidationError{
-			Field:   "order_type",
-			Message: "order type must be one of: dine_in, takeout, delivery",
-		})
-	}
-
-	// 3. Условная валидация в зависимости от order_type
-	switch req.OrderType {
-	case "dine_in":
-		// table_number обязателен
-		if req.TableNumber == nil {
-			errors = append(errors, ValidationError{
-				Field:   "table_number",
-				Message: "table number is required for dine-in orders",
-			})
-		} else if *req.TableNumber < 1 || *req.TableNumber > 100 {
-			errors = append(errors, ValidationError{
-				Field:   "table_number",
-				Message: "table number must be between 1 and 100",
-			})
-		}
+func (h *OrderHandler) releaseIdempotencyKey(r *http.Request, key, token string) {
+	if key == "" {
+		return
+	}
+	if err := h.idempotency.Release(r.Context(), principalSubject(r), key, token); err != nil {
+		h.logger.Error(r.Context(), "idempotency_failed", "Failed to release idempotency key", nil, err)
+	}
+}
 
-		// delivery_address не должен присутствовать
-		if req.DeliveryAddress != nil {
-			errors = append(errors, ValidationError{
-				Field:   "delivery_address",
-				Message: "delivery address must not be present for dine-in orders",
-			})
-		}
+// principalSubject возвращает аутентифицированного клиента запроса; без аутентификации - пустую строку
+func principalSubject(r *http.Request) string {
+	if p, ok := PrincipalFromContext(r.Context()); ok {
+		return p.Subject
+	}
+	return ""
+}
 
-	case "delivery":
-		// delivery_address обязателен
-		if req.DeliveryAddress == nil {
-			errors = append(errors, ValidationError{
-				Field:   "delivery_address",
-				Message: "delivery address is required for delivery orders",
-			})
-		} else if len(strings.TrimSpace(*req.DeliveryAddress)) < 10 {
-			errors = append(errors, ValidationError{
-				Field:   "delivery_address",
-				Message: "delivery address must be at least 10 characters",
-			})
+// customerSubject возвращает клиента, который оформляет заказ сам (роль customer);
+// заказы персонала учитываются в лимитах промокодов по имени клиента
+func customerSubject(r *http.Request) string {
+	p, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return ""
+	}
+	for _, role := range p.Roles {
+		if role == domain.RoleCustomer {
+			return p.Subject
 		}
+	}
+	return ""
+}
 
-		// table_number не должен присутствовать
-		if req.TableNumber != nil {
-			errors = append(errors, ValidationError{
-				Field:   "table_number",
-				Message: "table number must not be present for delivery orders",
-			})
-		}
+// hashRequest считает хеш нормализованного тела запроса, чтобы пробелы и порядок ключей в JSON не влияли на сравнение
+func hashRequest(req CreateOrderRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
 
-	case "takeout":
-		// Ни table_number, ни delivery_address не должны присутствовать
-		if req.TableNumber != nil {
-			errors = append(errors, ValidationError{
-				Field:   "table_number",
-				Message: "table number must not be present for takeout orders",
-			})
-		}
-		if req.DeliveryAddress != nil {
-			errors = append(errors, ValidationError{
-				Field:   "delivery_address",
-				Message: "delivery address must not be present for takeout orders",
-			})
-		}
+// HandleOrderActions обрабатывает действия над существующим заказом: /orders/{number}/{action}
+func (h *OrderHandler) HandleOrderActions(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 {
+		h.respondError(w, "Not found", http.StatusNotFound, nil)
+		return
 	}
 
-	// 4. Валидация items
-	if len(req.Items) < 1 {
-		errors = append(errors, ValidationError{
-			Field:   "items",
-			Message: "order must contain at least 1 item",
-		})
-	} else if len(req.Items) > 20 {
-		errors = append(errors, ValidationError{
-			Field:   "items",
-			Message: "order must not contain more than 20 items",
-		})
-	}
-
-	// 5. Валидация каждого item
-	for i, item := range req.Items {
-		itemPrefix := fmt.Sprintf("items[%d]", i)
-
-		// Валидация name
-		itemName := strings.TrimSpace(item.Name)
-		if len(itemName) < 1 {
-			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("%s.name", itemPrefix),
-				Message: "item name is required",
-			})
-		} else if len(itemName) > 50 {
-			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("%s.name", itemPrefix),
-				Message: "item name must not exceed 50 characters",
-			})
-		}
+	orderNumber := parts[1]
 
-		// Валидация quantity
-		if item.Quantity < 1 {
-			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("%s.quantity", itemPrefix),
-				Message: "item quantity must be at least 1",
-			})
-		} else if item.Quantity > 10 {
-			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("%s.quantity", itemPrefix),
-				Message: "item quantity must not exceed 10",
-			})
-		}
+	switch parts[2] {
+	case "cancel":
+		h.cancelOrder(w, r, orderNumber)
+	case "complete":
+		h.completeOrder(w, r, orderNumber)
+	default:
+		h.respondError(w, "Not found", http.StatusNotFound, nil)
+	}
+}
 
-		// Валидация price
-		if item.Price < 0.01 {
-			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("%s.price", itemPrefix),
-				Message: "item price must be at least 0.01",
-			})
-		} else if item.Price > 999.99 {
-			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("%s.price", itemPrefix),
-				Message: "item price must not exceed 999.99",
-			})
-		}
+func (h *OrderHandler) cancelOrder(w http.ResponseWriter, r *http.Request, orderNumber string) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req CancelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, "Invalid request body", http.StatusBadRequest, nil)
+		return
+	}
+
+	reason := domain.NormalizeText(req.Reason)
+	if length := domain.TextLength(reason); length < 1 || length > 255 {
+		h.respondError(w, "Validation failed", http.StatusBadRequest, []ValidationError{{
+			Field:   "reason",
+			Message: "reason must be 1-255 characters",
+		}})
+		return
+	}
+
+	note, ok := h.actorNote(w, "cancelled_by", req.CancelledBy)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.CancelOrder(r.Context(), interfaces.CancelOrderCommand{
+		OrderNumber: orderNumber,
+		Reason:      reason,
+		CancelledBy: actor(r),
+		Note:        note,
+	})
+	if err != nil {
+		h.respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(OrderStatusResponse{
+		OrderNumber: result.Number,
+		Status:      string(result.Status),
+	})
+}
+
+func (h *OrderHandler) completeOrder(w http.ResponseWriter, r *http.Request, orderNumber string) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req CompleteOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, "Invalid request body", http.StatusBadRequest, nil)
+		return
+	}
+
+	note, ok := h.actorNote(w, "completed_by", req.CompletedBy)
+	if !ok {
+		return
 	}
 
-	return errors
+	result, err := h.service.CompleteOrder(r.Context(), interfaces.CompleteOrderCommand{
+		OrderNumber: orderNumber,
+		CompletedBy: actor(r),
+		Note:        note,
+	})
+	if err != nil {
+		h.respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(OrderStatusResponse{
+		OrderNumber:  result.Number,
+		Status:       string(result.Status),
+		Handoff:      result.HandoffAction(),
+		HandedOverAt: result.HandedOverAt,
+	})
+}
+
+// actor возвращает автора действия для order_status_log: аутентифицированного клиента,
+// а без аутентификации - сам сервис
+func actor(r *http.Request) string {
+	if subject := principalSubject(r); subject != "" {
+		return subject
+	}
+	return "order-service"
+}
+
+// actorNote проверяет имя сотрудника из тела запроса: оно пишется в журнал как пометка и не заменяет автора
+func (h *OrderHandler) actorNote(w http.ResponseWriter, field, value string) (string, bool) {
+	note := domain.NormalizeText(value)
+	if domain.TextLength(note) > 100 {
+		h.respondError(w, "Validation failed", http.StatusBadRequest, []ValidationError{{
+			Field:   field,
+			Message: field + " must not exceed 100 characters",
+		}})
+		return "", false
+	}
+	return note, true
 }
 
 func convertItemsToCommand(items []OrderItemRequest) []interfaces.CreateOrderItemCommand {
 	result := make([]interfaces.CreateOrderItemCommand, len(items))
 	for i, item := range items {
 		result[i] = interfaces.CreateOrderItemCommand{
-			Name:     strings.TrimSpace(item.Name),
-			Quantity: item.Quantity,
-			Price:    item.Price,
+			MenuItemID:  item.MenuItemID,
+			Quantity:    item.Quantity,
+			ModifierIDs: item.ModifierIDs,
 		}
 	}
 	return result
 }
 
+// toValidationErrors переводит доменные ошибки полей в формат ответа API
+func toValidationErrors(errs domain.ValidationErrors) []ValidationError {
+	result := make([]ValidationError, len(errs))
+	for i, fe := range errs {
+		result[i] = ValidationError{Field: fe.Field, Message: fe.Message}
+	}
+	return result
+}
+
+func toPricingResponse(p domain.PriceBreakdown) PricingResponse {
+	taxes := make([]TaxLineResponse, len(p.Taxes))
+	for i, line := range p.Taxes {
+		taxes[i] = TaxLineResponse{Name: line.Name, RateBps: line.RateBps, Amount: line.Amount}
+	}
+
+	return PricingResponse{
+		Subtotal:      p.Subtotal,
+		Discount:      p.Discount,
+		PromoCode:     p.PromoCode,
+		ServiceCharge: p.ServiceCharge,
+		DeliveryFee:   p.DeliveryFee,
+		Taxes:         taxes,
+		Total:         p.Total,
+	}
+}
+
+// respondServiceError переводит доменные ошибки в HTTP статусы
+func (h *OrderHandler) respondServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound):
+		h.respondError(w, "Order not found", http.StatusNotFound, nil)
+	case errors.Is(err, domain.ErrInvalidStatusTransition):
+		h.respondError(w, "Order status does not allow this action", http.StatusConflict, nil)
+	default:
+		h.logger.Error(r.Context(), "order_action_failed", "Failed to update order", nil, err)
+		h.respondError(w, "Internal server error", http.StatusInternalServerError, nil)
+	}
+}
+
 func (h *OrderHandler) respondError(w http.ResponseWriter, message string, statusCode int, validationErrors []ValidationError) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+type PromotionHandler struct {
+	service interfaces.PromotionService
+	logger  logger.Logger
+}
+
+func NewPromotionHandler(service interfaces.PromotionService, logger logger.Logger) *PromotionHandler {
+	return &PromotionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type PromotionRequest struct {
+	Code               string       `json:"code"`
+	Description        string       `json:"description"`
+	Kind               string       `json:"kind"`
+	PercentBps         int64        `json:"percent_bps,omitempty"`
+	Amount             domain.Money `json:"amount"`
+	MenuItemID         int          `json:"menu_item_id,omitempty"`
+	BuyQuantity        int          `json:"buy_quantity,omitempty"`
+	FreeQuantity       int          `json:"free_quantity,omitempty"`
+	MinSubtotal        domain.Money `json:"min_subtotal"`
+	ValidFrom          *time.Time   `json:"valid_from,omitempty"`
+	ValidTo            *time.Time   `json:"valid_to,omitempty"`
+	MaxUses            *int         `json:"max_uses,omitempty"`
+	MaxUsesPerCustomer *int         `json:"max_uses_per_customer,omitempty"`
+}
+
+type PromotionResponse struct {
+	ID                 int          `json:"id"`
+	Code               string       `json:"code"`
+	Description        string       `json:"description"`
+	Kind               string       `json:"kind"`
+	PercentBps         int64        `json:"percent_bps"`
+	Amount             domain.Money `json:"amount"`
+	MenuItemID         int          `json:"menu_item_id,omitempty"`
+	BuyQuantity        int          `json:"buy_quantity"`
+	FreeQuantity       int          `json:"free_quantity"`
+	MinSubtotal        domain.Money `json:"min_subtotal"`
+	ValidFrom          *time.Time   `json:"valid_from"`
+	ValidTo            *time.Time   `json:"valid_to"`
+	MaxUses            *int         `json:"max_uses"`
+	MaxUsesPerCustomer *int         `json:"max_uses_per_customer"`
+	TimesUsed          int          `json:"times_used"`
+	Active             bool         `json:"active"`
+	CreatedAt          time.Time    `json:"created_at"`
+}
+
+// HandleAdminPromotions обрабатывает /admin/promotions: список промокодов и создание нового
+func (h *PromotionHandler) HandleAdminPromotions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		promotions, err := h.service.ListPromotions(r.Context())
+		if err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		resp := make([]PromotionResponse, len(promotions))
+		for i, p := range promotions {
+			resp[i] = toPromotionResponse(p)
+		}
+		h.respondJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req PromotionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, "Invalid request body", http.StatusBadRequest, nil)
+			return
+		}
+		promotion, err := h.service.CreatePromotion(r.Context(), interfaces.PromotionCommand{
+			Code:               req.Code,
+			Description:        strings.TrimSpace(req.Description),
+			Kind:               domain.PromotionKind(req.Kind),
+			PercentBps:         req.PercentBps,
+			Amount:             req.Amount,
+			MenuItemID:         req.MenuItemID,
+			BuyQuantity:        req.BuyQuantity,
+			FreeQuantity:       req.FreeQuantity,
+			MinSubtotal:        req.MinSubtotal,
+			ValidFrom:          req.ValidFrom,
+			ValidTo:            req.ValidTo,
+			MaxUses:            req.MaxUses,
+			MaxUsesPerCustomer: req.MaxUsesPerCustomer,
+		})
+		if err != nil {
+			h.respondServiceError(w, r, err)
+			return
+		}
+		h.respondJSON(w, http.StatusCreated, toPromotionResponse(promotion))
+
+	default:
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// HandleAdminPromotion обрабатывает DELETE /admin/promotions/{id}: промокод деактивируется, а не удаляется
+func (h *PromotionHandler) HandleAdminPromotion(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 {
+		h.respondError(w, "Not found", http.StatusNotFound, nil)
+		return
+	}
+
+	id, err := strconv.Atoi(parts[2])
+	if err != nil || id < 1 {
+		h.respondError(w, "Not found", http.StatusNotFound, nil)
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		h.respondError(w, "Method not allowed", http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	if err := h.service.DeactivatePromotion(r.Context(), id); err != nil {
+		h.respondServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toPromotionResponse(p *domain.Promotion) PromotionResponse {
+	return PromotionResponse{
+		ID:                 p.ID,
+		Code:               p.Code,
+		Description:        p.Description,
+		Kind:               string(p.Kind),
+		PercentBps:         p.PercentBps,
+		Amount:             p.Amount,
+		MenuItemID:         p.MenuItemID,
+		BuyQuantity:        p.BuyQuantity,
+		FreeQuantity:       p.FreeQuantity,
+		MinSubtotal:        p.MinSubtotal,
+		ValidFrom:          p.ValidFrom,
+		ValidTo:            p.ValidTo,
+		MaxUses:            p.MaxUses,
+		MaxUsesPerCustomer: p.MaxUsesPerCustomer,
+		TimesUsed:          p.TimesUsed,
+		Active:             p.Active,
+		CreatedAt:          p.CreatedAt,
+	}
+}
+
+func (h *PromotionHandler) respondServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrors domain.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		h.respondError(w, "Validation failed", http.StatusBadRequest, toValidationErrors(validationErrors))
+	case errors.Is(err, domain.ErrPromotionNotFound):
+		h.respondError(w, "Promotion not found", http.StatusNotFound, nil)
+	case errors.Is(err, domain.ErrPromotionCodeExists):
+		h.respondError(w, "Promo code already exists", http.StatusConflict, nil)
+	default:
+		h.logger.Error(r.Context(), "promotion_request_failed", "Promotion request failed", nil, err)
+		h.respondError(w, "Internal server error", http.StatusInternalServerError, nil)
+	}
+}
+
+func (h *PromotionHandler) respondJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (h *PromotionHandler) respondError(w http.ResponseWriter, message string, statusCode int, validationErrors []ValidationError) {
+	h.respondJSON(w, statusCode, ErrorResponse{
+		Error:  message,
+		Errors: validationErrors,
+	})
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/config"
+)
+
+// RateLimit - параметры token bucket: скорость пополнения и емкость
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+type routeLimit struct {
+	method string
+	prefix string
+	limit  RateLimit
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter хранит token bucket на каждую пару (клиент, правило).
+// Клиент - аутентифицированный principal (middleware стоит после AuthMiddleware), иначе IP-адрес:
+// непроверенные заголовки не дают новый bucket на каждый запрос.
+type RateLimiter struct {
+	defaultLimit      RateLimit
+	routes            []routeLimit
+	trustForwardedFor bool
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+const bucketIdleTTL = 10 * time.Minute
+
+func NewRateLimiter(defaultLimit RateLimit, routes []config.RouteLimitConfig, trustForwardedFor bool) *RateLimiter {
+	l := &RateLimiter{
+		defaultLimit:      defaultLimit,
+		trustForwardedFor: trustForwardedFor,
+		buckets:           make(map[string]*tokenBucket),
+		lastSweep:         time.Now(),
+	}
+
+	for _, r := range routes {
+		l.routes = append(l.routes, routeLimit{
+			method: r.Method,
+			prefix: r.Prefix,
+			limit:  RateLimit{RequestsPerSecond: r.RequestsPerSecond, Burst: r.Burst},
+		})
+	}
+	// Самый длинный префикс проверяется первым, правило с методом - раньше правила без метода
+	sort.SliceStable(l.routes, func(i, j int) bool {
+		if len(l.routes[i].prefix) != len(l.routes[j].prefix) {
+			return len(l.routes[i].prefix) > len(l.routes[j].prefix)
+		}
+		return l.routes[i].method != "" && l.routes[j].method == ""
+	})
+
+	return l
+}
+
+// rateDecision - результат проверки лимита для заголовков ответа
+type rateDecision struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func (l *RateLimiter) match(r *http.Request) (string, RateLimit) {
+	for _, route := range l.routes {
+		if route.method != "" && route.method != r.Method {
+			continue
+		}
+		if strings.HasPrefix(r.URL.Path, route.prefix) {
+			return route.method + " " + route.prefix, route.limit
+		}
+	}
+	return "default", l.defaultLimit
+}
+
+func (l *RateLimiter) take(key string, limit RateLimit, now time.Time) rateDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.RequestsPerSecond)
+	b.last = now
+
+	d := rateDecision{limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.allowed = true
+	} else {
+		d.retryAfter = secondsToDuration((1 - b.tokens) / limit.RequestsPerSecond)
+	}
+	d.remaining = int(b.tokens)
+	d.reset = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.RequestsPerSecond)
+
+	return d
+}
+
+// refund возвращает токен, взятый take: запрос не должен учитываться в лимите
+func (l *RateLimiter) refund(key string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+1)
+	}
+}
+
+// sweep удаляет bucket'ы неактивных клиентов, чтобы карта не росла бесконечно
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.Subject
+	}
+
+	// Клиент сам задает начало X-Forwarded-For, доверять можно только адресу,
+	// который дописал наш прокси последним
+	if l.trustForwardedFor {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			hops := strings.Split(values[len(values)-1], ",")
+			if client := strings.TrimSpace(hops[len(hops)-1]); client != "" {
+				return "ip:" + client
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func RateLimitMiddleware(limiter *RateLimiter, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, limit := limiter.match(r)
+			if limit.RequestsPerSecond <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := limiter.clientKey(r)
+			d := limiter.take(client+"|"+rule, limit, time.Now())
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(d.limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(d.remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(d.reset)))
+
+			if !d.allowed {
+				logger.Debug(r.Context(), "rate_limited", fmt.Sprintf("Rate limit exceeded for %s", rule), map[string]interface{}{
+					"client": client,
+					"path":   r.URL.Path,
+				})
+				rejectRequest(w, http.StatusTooManyRequests, d.retryAfter, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthFailureLimitMiddleware ограничивает неудачные попытки аутентификации с одного IP лимитом по умолчанию limiter.
+// Стоит перед AuthMiddleware: запрос с неверным ключом или JWT получает 401 раньше RateLimitMiddleware,
+// и без этого подбор учетных данных ничем не ограничен. Токен резервируется до запроса (параллельные попытки
+// не проскочат лимит) и возвращается, если ответ не 401: успешные запросы лимит не расходуют.
+func AuthFailureLimitMiddleware(limiter *RateLimiter, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// principal еще не установлен, поэтому ключ - IP-адрес
+			client := limiter.clientKey(r)
+			key := client + "|auth_failures"
+			limit := limiter.defaultLimit
+
+			d := limiter.take(key, limit, time.Now())
+			if !d.allowed {
+				logger.Debug(r.Context(), "auth_rate_limited", "Too many failed authentication attempts", map[string]interface{}{
+					"client": client,
+					"path":   r.URL.Path,
+				})
+				rejectRequest(w, http.StatusTooManyRequests, d.retryAfter, "Too many failed authentication attempts")
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status != http.StatusUnauthorized {
+				limiter.refund(key, limit)
+			}
+		})
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -1,12 +1,18 @@
 package http
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
 
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 )
@@ -24,8 +30,7 @@ func NewTrackingHandler(service interfaces.TrackingService, logger logger.Logger
 }
 
 func (h *TrackingHandler) HandleOrders(w http.ResponseWriter, r *http.Request) {
-	requestID := time.Now().UnixNano()
-	h.logger.Debug("request_received", "Request received", string(rune(requestID)), map[string]interface{}{
+	h.logger.Debug(r.Context(), "request_received", "Request received", map[string]interface{}{
 		"path": r.URL.Path,
 	})
 
@@ -64,6 +69,7 @@ func (h *TrackingHandler) getOrderStatus(w http.ResponseWriter, r *http.Request,
 		"updated_at":           result.UpdatedAt,
 		"estimated_completion": result.EstimatedCompletion,
 		"processed_by":         result.ProcessedBy,
+		"handed_over_at":       result.HandedOverAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -101,7 +107,7 @@ func (h *TrackingHandler) GetWorkersStatus(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	h.logger.Debug("request_received", "Workers status requested", "", nil)
+	h.logger.Debug(r.Context(), "request_received", "Workers status requested", nil)
 
 	workers, err := h.service.GetWorkersStatus(r.Context())
 	if err != nil {
@@ -115,6 +121,8 @@ func (h *TrackingHandler) GetWorkersStatus(w http.ResponseWriter, r *http.Reques
 			"worker_name":      worker.WorkerName,
 			"status":           worker.Status,
 			"orders_processed": worker.OrdersProcessed,
+			"capacity":         worker.Capacity,
+			"current_load":     worker.CurrentLoad,
 			"last_seen":        worker.LastSeen,
 		}
 	}
@@ -122,3 +130,220 @@ func (h *TrackingHandler) GetWorkersStatus(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// orderListCursor - содержимое непрозрачного курсора пагинации
+type orderListCursor struct {
+	Sort       interfaces.OrderSortField `json:"s"`
+	Descending bool                      `json:"d"`
+	Value      string                    `json:"v"`
+	ID         int                       `json:"id"`
+}
+
+// ListOrders обрабатывает GET /orders с фильтрами, сортировкой и курсорной пагинацией
+func (h *TrackingHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, validationErrors := parseOrderFilter(r.URL.Query())
+	if len(validationErrors) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Validation failed", Errors: validationErrors})
+		return
+	}
+
+	result, err := h.service.ListOrders(r.Context(), filter)
+	if err != nil {
+		h.logger.Error(r.Context(), "db_query_failed", "Failed to list orders", nil, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	orders := make([]map[string]interface{}, len(result.Orders))
+	for i, o := range result.Orders {
+		orders[i] = map[string]interface{}{
+			"order_number":   o.Number,
+			"customer_name":  o.CustomerName,
+			"order_type":     o.Type,
+			"status":         o.Status,
+			"priority":       o.Priority,
+			"total_amount":   o.TotalAmount,
+			"currency":       o.Currency,
+			"processed_by":   o.ProcessedBy,
+			"created_at":     o.CreatedAt,
+			"updated_at":     o.UpdatedAt,
+			"completed_at":   o.CompletedAt,
+			"handed_over_at": o.HandedOverAt,
+		}
+	}
+
+	resp := map[string]interface{}{
+		"orders":      orders,
+		"next_cursor": nil,
+	}
+	if result.NextCursor != nil {
+		resp["next_cursor"] = encodeOrderCursor(orderListCursor{
+			Sort:       filter.SortBy,
+			Descending: filter.Descending,
+			Value:      result.NextCursor.SortValue,
+			ID:         result.NextCursor.ID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func parseOrderFilter(q url.Values) (interfaces.OrderFilter, []ValidationError) {
+	var errors []ValidationError
+	filter := interfaces.OrderFilter{
+		SortBy:     interfaces.OrderSortCreatedAt,
+		Descending: true,
+	}
+
+	validStatuses := map[domain.Status]bool{
+		domain.StatusReceived:  true,
+		domain.StatusCooking:   true,
+		domain.StatusReady:     true,
+		domain.StatusCompleted: true,
+		domain.StatusCancelled: true,
+	}
+	for _, v := range splitList(q.Get("status")) {
+		if !validStatuses[domain.Status(v)] {
+			errors = append(errors, ValidationError{Field: "status", Message: fmt.Sprintf("unknown status: %s", v)})
+			continue
+		}
+		filter.Statuses = append(filter.Statuses, domain.Status(v))
+	}
+
+	validTypes := map[domain.OrderType]bool{
+		domain.OrderTypeDineIn:   true,
+		domain.OrderTypeTakeout:  true,
+		domain.OrderTypeDelivery: true,
+	}
+	for _, v := range splitList(q.Get("order_type")) {
+		if !validTypes[domain.OrderType(v)] {
+			errors = append(errors, ValidationError{Field: "order_type", Message: fmt.Sprintf("unknown order type: %s", v)})
+			continue
+		}
+		filter.Types = append(filter.Types, domain.OrderType(v))
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := parseTimeParam(v, false)
+		if err != nil {
+			errors = append(errors, ValidationError{Field: "from", Message: "from must be RFC3339 or YYYY-MM-DD"})
+		} else {
+			filter.CreatedFrom = &from
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := parseTimeParam(v, true)
+		if err != nil {
+			errors = append(errors, ValidationError{Field: "to", Message: "to must be RFC3339 or YYYY-MM-DD"})
+		} else {
+			filter.CreatedTo = &to
+		}
+	}
+
+	filter.CustomerName = strings.TrimSpace(q.Get("customer_name"))
+	filter.ProcessedBy = strings.TrimSpace(q.Get("processed_by"))
+
+	if v := q.Get("priority"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || (domain.Priority(p) != domain.PriorityLow && domain.Priority(p) != domain.PriorityMedium && domain.Priority(p) != domain.PriorityHigh) {
+			errors = append(errors, ValidationError{Field: "priority", Message: "priority must be one of: 1, 5, 10"})
+		} else {
+			priority := domain.Priority(p)
+			filter.Priority = &priority
+		}
+	}
+
+	// sort=created_at по возрастанию, sort=-created_at по убыванию
+	if v := q.Get("sort"); v != "" {
+		filter.Descending = strings.HasPrefix(v, "-")
+		field := interfaces.OrderSortField(strings.TrimPrefix(v, "-"))
+		switch field {
+		case interfaces.OrderSortCreatedAt, interfaces.OrderSortUpdatedAt, interfaces.OrderSortPriority:
+			filter.SortBy = field
+		default:
+			errors = append(errors, ValidationError{Field: "sort", Message: "sort must be one of: created_at, updated_at, priority (prefix with - for descending)"})
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			errors = append(errors, ValidationError{Field: "limit", Message: "limit must be between 1 and 100"})
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := decodeOrderCursor(v)
+		if err != nil || cursor.Sort != filter.SortBy || cursor.Descending != filter.Descending || !cursor.valid() {
+			errors = append(errors, ValidationError{Field: "cursor", Message: "cursor is invalid or does not match the sort order"})
+		} else {
+			filter.After = &interfaces.OrderCursor{SortValue: cursor.Value, ID: cursor.ID}
+		}
+	}
+
+	return filter, errors
+}
+
+func splitList(v string) []string {
+	var result []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// parseTimeParam принимает RFC3339 или дату. Дата в параметре "to" включает весь день.
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// valid проверяет значения курсора, который клиент может подделать: они уходят в запрос
+// с приведением типа (::timestamptz, ::integer), и неверное значение дало бы 500 вместо 400
+func (c orderListCursor) valid() bool {
+	if c.ID < 1 || c.ID > math.MaxInt32 {
+		return false
+	}
+	if c.Sort == interfaces.OrderSortPriority {
+		_, err := strconv.ParseInt(c.Value, 10, 32)
+		return err == nil
+	}
+	_, err := time.Parse(time.RFC3339Nano, c.Value)
+	return err == nil
+}
+
+func encodeOrderCursor(c orderListCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeOrderCursor(v string) (orderListCursor, error) {
+	var c orderListCursor
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -1,16 +1,18 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"sync"
 	"time"
 )
 
+// Logger пишет JSON-логи; request_id берется из контекста (WithRequestID)
 type Logger interface {
-	Info(action, message, requestID string, details map[string]interface{})
-	Debug(action, message, requestID string, details map[string]interface{})
-	Error(action, message, requestID string, details map[string]interface{}, err error)
+	Info(ctx context.Context, action, message string, details map[string]interface{})
+	Debug(ctx context.Context, action, message string, details map[string]interface{})
+	Error(ctx context.Context, action, message string, details map[string]interface{}, err error)
 }
 
 type jsonLogger struct {
@@ -27,16 +29,16 @@ func New(service string) Logger {
 	}
 }
 
-func (l *jsonLogger) Info(action, message, requestID string, details map[string]interface{}) {
-	l.log("INFO", action, message, requestID, details, nil)
+func (l *jsonLogger) Info(ctx context.Context, action, message string, details map[string]interface{}) {
+	l.log("INFO", action, message, RequestID(ctx), details, nil)
 }
 
-func (l *jsonLogger) Debug(action, message, requestID string, details map[string]interface{}) {
-	l.log("DEBUG", action, message, requestID, details, nil)
+func (l *jsonLogger) Debug(ctx context.Context, action, message string, details map[string]interface{}) {
+	l.log("DEBUG", action, message, RequestID(ctx), details, nil)
 }
 
-func (l *jsonLogger) Error(action, message, requestID string, details map[string]interface{}, err error) {
-	l.log("ERROR", action, message, requestID, details, err)
+func (l *jsonLogger) Error(ctx context.Context, action, message string, details map[string]interface{}, err error) {
+	l.log("ERROR", action, message, RequestID(ctx), details, err)
 }
 
 func (l *jsonLogger) log(level, action, message, requestID string, details map[string]interface{}, err error) {
@@ -0,0 +1,28 @@
+package metrics
+
+// Default - реестр процесса; его отдает /metrics в каждом режиме
+var Default = NewRegistry()
+
+// Метрики сервисов. Метки держим с ограниченным набором значений (маршрут-шаблон, тип заказа,
+// имя воркера), чтобы число серий не росло с количеством заказов.
+var (
+	HTTPRequests = Default.NewCounterVec("http_requests_total",
+		"HTTP requests handled, by route pattern, method and status code", "route", "method", "status")
+	HTTPRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency in seconds, by route pattern and method", DefaultDurationBuckets, "route", "method")
+
+	OrdersCreated = Default.NewCounterVec("orders_created_total",
+		"Orders accepted by the order service, by order type", "order_type")
+
+	PublishRetries = Default.NewCounterVec("rabbitmq_publish_retries_total",
+		"Publish attempts repeated after a failure, by exchange", "exchange")
+	PublishFailures = Default.NewCounterVec("rabbitmq_publish_failures_total",
+		"Publishes that failed after all retries, by exchange", "exchange")
+	ConsumedMessages = Default.NewCounterVec("rabbitmq_consumed_messages_total",
+		"Delivered messages by queue and outcome (ack, nack, requeue, retry)", "queue", "outcome")
+
+	CookingDuration = Default.NewHistogramVec("kitchen_cooking_duration_seconds",
+		"Time from cooking start to ready, by worker", []float64{5, 8, 10, 12, 15, 20, 30, 60}, "worker")
+	HeartbeatFailures = Default.NewCounterVec("kitchen_heartbeat_failures_total",
+		"Failed worker heartbeat updates, by worker", "worker")
+)
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/config"
 	"github.com/jackc/pgx/v5"
@@ -15,9 +16,22 @@ type DB interface {
 	QueryRow(ctx context.Context, sql string, args ...any) Row
 	Exec(ctx context.Context, sql string, args ...any) (CommandTag, error)
 	Begin(ctx context.Context) (Tx, error)
+	Ping(ctx context.Context) error
+	Stats() PoolStats
 	Close()
 }
 
+// PoolStats - состояние пула соединений для метрик
+type PoolStats struct {
+	TotalConns        int32
+	IdleConns         int32
+	AcquiredConns     int32
+	MaxConns          int32
+	AcquireCount      int64
+	EmptyAcquireCount int64
+	AcquireDuration   time.Duration
+}
+
 type Rows interface {
 	Next() bool
 	Scan(dest ...any) error
@@ -91,6 +105,23 @@ func (db *pgxDB) Begin(ctx context.Context) (Tx, error) {
 	return &pgxTx{tx: tx}, nil
 }
 
+func (db *pgxDB) Ping(ctx context.Context) error {
+	return db.pool.Ping(ctx)
+}
+
+func (db *pgxDB) Stats() PoolStats {
+	stat := db.pool.Stat()
+	return PoolStats{
+		TotalConns:        stat.TotalConns(),
+		IdleConns:         stat.IdleConns(),
+		AcquiredConns:     stat.AcquiredConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+		AcquireDuration:   stat.AcquireDuration(),
+	}
+}
+
 func (db *pgxDB) Close() {
 	db.pool.Close()
 }
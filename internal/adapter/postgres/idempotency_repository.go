@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+	"github.com/jackc/pgx/v5"
+)
+
+type idempotencyRepository struct {
+	db DB
+}
+
+func NewIdempotencyRepository(db DB) interfaces.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve занимает ключ клиента principal на время lease от имени запроса token. Если ключ уже существует,
+// возвращает сохраненную запись, если ключ занят впервые или перехвачен у запроса, чья аренда истекла
+// (процесс упал до Complete), - nil.
+func (r *idempotencyRepository) Reserve(ctx context.Context, principal, key, token, requestHash string, lease time.Duration) (*domain.IdempotencyRecord, error) {
+	insertQuery := `
+		INSERT INTO idempotency_keys (principal, key, lease_token, request_hash, status, created_at, locked_until)
+		VALUES ($1, $2, $3, $4, 'in_progress', $5, $6)
+		ON CONFLICT (principal, key) DO UPDATE
+		SET lease_token = EXCLUDED.lease_token, request_hash = EXCLUDED.request_hash,
+		    created_at = EXCLUDED.created_at, locked_until = EXCLUDED.locked_until
+		WHERE idempotency_keys.status = 'in_progress' AND idempotency_keys.locked_until < EXCLUDED.created_at
+	`
+	now := time.Now()
+	tag, err := r.db.Exec(ctx, insertQuery, principal, key, token, requestHash, now, now.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT key, request_hash, status = 'completed', COALESCE(response_code, 0), response_body, created_at
+		FROM idempotency_keys
+		WHERE principal = $1 AND key = $2
+	`
+
+	var record domain.IdempotencyRecord
+	err = r.db.QueryRow(ctx, query, principal, key).Scan(
+		&record.Key, &record.RequestHash, &record.Completed, &record.ResponseCode, &record.ResponseBody, &record.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Ключ освободили между INSERT и SELECT, клиент может повторить запрос
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// Complete сохраняет ответ, если ключ все еще занят запросом token; иначе - ErrIdempotencyKeyReclaimed
+func (r *idempotencyRepository) Complete(ctx context.Context, principal, key, token string, responseCode int, responseBody []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status = 'completed', response_code = $1, response_body = $2, completed_at = $3, locked_until = NULL
+		WHERE principal = $4 AND key = $5 AND lease_token = $6 AND status = 'in_progress'
+	`
+	tag, err := r.db.Exec(ctx, query, responseCode, responseBody, time.Now(), principal, key, token)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrIdempotencyKeyReclaimed
+	}
+	return nil
+}
+
+// Release удаляет ключ, только если он все еще занят запросом token
+func (r *idempotencyRepository) Release(ctx context.Context, principal, key, token string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE principal = $1 AND key = $2 AND lease_token = $3 AND status = 'in_progress'
+	`
+	_, err := r.db.Exec(ctx, query, principal, key, token)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет ключ, созданный раньше createdBefore, чтобы его можно было занять заново
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, principal, key string, createdBefore time.Time) error {
+	query := `DELETE FROM idempotency_keys WHERE principal = $1 AND key = $2 AND created_at < $3`
+	_, err := r.db.Exec(ctx, query, principal, key, createdBefore)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type menuRepository struct {
+	db DB
+}
+
+func NewMenuRepository(db DB) interfaces.MenuRepository {
+	return &menuRepository{db: db}
+}
+
+const menuItemColumns = `id, name, description, category, price, available, created_at, updated_at`
+
+func (r *menuRepository) List(ctx context.Context, onlyAvailable bool) ([]*domain.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM menu_items`
+	if onlyAvailable {
+		query += ` WHERE available`
+	}
+	query += ` ORDER BY category, name`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list menu items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*domain.MenuItem
+	for rows.Next() {
+		item, err := scanMenuItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := r.attachModifierGroups(ctx, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *menuRepository) FindByID(ctx context.Context, id int) (*domain.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM menu_items WHERE id = $1`
+
+	item, err := scanMenuItem(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrMenuItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.attachModifierGroups(ctx, []*domain.MenuItem{item}); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// FindByIDs загружает позиции меню одним запросом; отсутствующих id в результате нет
+func (r *menuRepository) FindByIDs(ctx context.Context, ids []int) (map[int]*domain.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM menu_items WHERE id = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load menu items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make(map[int]*domain.MenuItem, len(ids))
+	var list []*domain.MenuItem
+	for rows.Next() {
+		item, err := scanMenuItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items[item.ID] = item
+		list = append(list, item)
+	}
+
+	if err := r.attachModifierGroups(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *menuRepository) Create(ctx context.Context, item *domain.MenuItem) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO menu_items (name, description, category, price, available, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err = tx.QueryRow(ctx, query,
+		item.Name, item.Description, item.Category, moneyArg(item.Price), item.Available, item.CreatedAt, item.UpdatedAt,
+	).Scan(&item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create menu item: %w", err)
+	}
+
+	if err := saveModifierGroups(ctx, tx, item); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Update сохраняет группы модификаторов позиции по ID: существующие обновляются и сохраняют ID,
+// которые держат клиенты с загруженным меню; удаляются только группы и модификаторы, убранные из позиции.
+// Уже оформленные заказы не меняются: в order_item_modifiers хранятся копии названий и цен.
+func (r *menuRepository) Update(ctx context.Context, item *domain.MenuItem) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE menu_items
+		SET name = $1, description = $2, category = $3, price = $4, available = $5, updated_at = $6
+		WHERE id = $7
+	`
+	tag, err := tx.Exec(ctx, query,
+		item.Name, item.Description, item.Category, moneyArg(item.Price), item.Available, item.UpdatedAt, item.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update menu item: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMenuItemNotFound
+	}
+
+	if err := saveModifierGroups(ctx, tx, item); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *menuRepository) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM menu_items WHERE id = $1`, id)
+	// promotions.menu_item_id ON DELETE RESTRICT: промо на конкретную позицию не должно стать промо на любую
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return domain.ErrMenuItemInUse
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete menu item: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMenuItemNotFound
+	}
+	return nil
+}
+
+func scanMenuItem(row Row) (*domain.MenuItem, error) {
+	var item domain.MenuItem
+	err := row.Scan(
+		&item.ID, &item.Name, &item.Description, &item.Category, scanMoney(&item.Price),
+		&item.Available, &item.CreatedAt, &item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan menu item: %w", err)
+	}
+	return &item, nil
+}
+
+// attachModifierGroups загружает группы модификаторов для позиций одним запросом
+func (r *menuRepository) attachModifierGroups(ctx context.Context, items []*domain.MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*domain.MenuItem, len(items))
+	ids := make([]int, len(items))
+	for i, item := range items {
+		byID[item.ID] = item
+		ids[i] = item.ID
+	}
+
+	query := `
+		SELECT g.id, g.menu_item_id, g.name, g.min_select, g.max_select,
+		       m.id, m.name, m.price, m.available
+		FROM modifier_groups g
+		JOIN modifiers m ON m.group_id = g.id
+		WHERE g.menu_item_id = ANY($1)
+		ORDER BY g.menu_item_id, g.position, g.id, m.position, m.id
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load modifier groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group domain.ModifierGroup
+		var mod domain.Modifier
+		if err := rows.Scan(
+			&group.ID, &group.MenuItemID, &group.Name, &group.MinSelect, &group.MaxSelect,
+			&mod.ID, &mod.Name, scanMoney(&mod.Price), &mod.Available,
+		); err != nil {
+			return fmt.Errorf("failed to scan modifier: %w", err)
+		}
+		mod.GroupID = group.ID
+
+		item := byID[group.MenuItemID]
+		last := len(item.ModifierGroups) - 1
+		if last < 0 || item.ModifierGroups[last].ID != group.ID {
+			item.ModifierGroups = append(item.ModifierGroups, group)
+			last++
+		}
+		item.ModifierGroups[last].Modifiers = append(item.ModifierGroups[last].Modifiers, mod)
+	}
+
+	return nil
+}
+
+// saveModifierGroups вставляет группы и модификаторы без ID, обновляет остальные
+// и удаляет те, что есть в базе, но отсутствуют в item
+func saveModifierGroups(ctx context.Context, tx Tx, item *domain.MenuItem) error {
+	insertGroup := `
+		INSERT INTO modifier_groups (menu_item_id, name, min_select, max_select, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	updateGroup := `
+		UPDATE modifier_groups
+		SET name = $1, min_select = $2, max_select = $3, position = $4
+		WHERE id = $5 AND menu_item_id = $6
+	`
+	insertMod := `
+		INSERT INTO modifiers (group_id, name, price, available, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	updateMod := `
+		UPDATE modifiers
+		SET name = $1, price = $2, available = $3, position = $4
+		WHERE id = $5 AND group_id = $6
+	`
+
+	groupIDs := make([]int, 0, len(item.ModifierGroups))
+	for i := range item.ModifierGroups {
+		group := &item.ModifierGroups[i]
+		group.MenuItemID = item.ID
+
+		if group.ID == 0 {
+			err := tx.QueryRow(ctx, insertGroup, item.ID, group.Name, group.MinSelect, group.MaxSelect, i).Scan(&group.ID)
+			if err != nil {
+				return fmt.Errorf("failed to insert modifier group: %w", err)
+			}
+		} else {
+			tag, err := tx.Exec(ctx, updateGroup, group.Name, group.MinSelect, group.MaxSelect, i, group.ID, item.ID)
+			if err != nil {
+				return fmt.Errorf("failed to update modifier group: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				return fmt.Errorf("modifier group %d does not belong to menu item %d", group.ID, item.ID)
+			}
+		}
+		groupIDs = append(groupIDs, group.ID)
+
+		modIDs := make([]int, 0, len(group.Modifiers))
+		for j := range group.Modifiers {
+			mod := &group.Modifiers[j]
+			mod.GroupID = group.ID
+
+			if mod.ID == 0 {
+				err := tx.QueryRow(ctx, insertMod, group.ID, mod.Name, moneyArg(mod.Price), mod.Available, j).Scan(&mod.ID)
+				if err != nil {
+					return fmt.Errorf("failed to insert modifier: %w", err)
+				}
+			} else {
+				tag, err := tx.Exec(ctx, updateMod, mod.Name, moneyArg(mod.Price), mod.Available, j, mod.ID, group.ID)
+				if err != nil {
+					return fmt.Errorf("failed to update modifier: %w", err)
+				}
+				if tag.RowsAffected() == 0 {
+					return fmt.Errorf("modifier %d does not belong to modifier group %d", mod.ID, group.ID)
+				}
+			}
+			modIDs = append(modIDs, mod.ID)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM modifiers WHERE group_id = $1 AND NOT (id = ANY($2))`, group.ID, modIDs); err != nil {
+			return fmt.Errorf("failed to delete removed modifiers: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM modifier_groups WHERE menu_item_id = $1 AND NOT (id = ANY($2))`, item.ID, groupIDs); err != nil {
+		return fmt.Errorf("failed to delete removed modifier groups: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
+)
+
+// RegisterPoolMetrics публикует статистику пула соединений; значения читаются при каждом сборе
+func RegisterPoolMetrics(reg *metrics.Registry, db DB) {
+	reg.NewGaugeFunc("db_pool_total_connections", "Open connections in the pool", func() float64 {
+		return float64(db.Stats().TotalConns)
+	})
+	reg.NewGaugeFunc("db_pool_idle_connections", "Idle connections in the pool", func() float64 {
+		return float64(db.Stats().IdleConns)
+	})
+	reg.NewGaugeFunc("db_pool_acquired_connections", "Connections currently in use", func() float64 {
+		return float64(db.Stats().AcquiredConns)
+	})
+	reg.NewGaugeFunc("db_pool_max_connections", "Maximum pool size", func() float64 {
+		return float64(db.Stats().MaxConns)
+	})
+	reg.NewCounterFunc("db_pool_acquires_total", "Connections acquired from the pool", func() float64 {
+		return float64(db.Stats().AcquireCount)
+	})
+	reg.NewCounterFunc("db_pool_empty_acquires_total", "Acquires that had to wait because the pool was empty", func() float64 {
+		return float64(db.Stats().EmptyAcquireCount)
+	})
+	reg.NewCounterFunc("db_pool_acquire_duration_seconds_total", "Total time spent waiting to acquire a connection", func() float64 {
+		return db.Stats().AcquireDuration.Seconds()
+	})
+}
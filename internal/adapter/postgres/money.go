@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+)
+
+// moneyArg передает сумму в DECIMAL колонку текстом, чтобы не терять точность на float
+func moneyArg(m domain.Money) string {
+	return m.String()
+}
+
+// moneyColumn читает DECIMAL колонку в domain.Money через текстовое представление.
+// Валюта в колонке не хранится, ее проставляет вызывающий код.
+type moneyColumn struct {
+	dest *domain.Money
+}
+
+func scanMoney(dest *domain.Money) moneyColumn {
+	return moneyColumn{dest: dest}
+}
+
+func (c moneyColumn) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*c.dest = domain.Money{}
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+
+	m, err := domain.ParseMoney(text, "")
+	if err != nil {
+		return err
+	}
+	*c.dest = m
+	return nil
+}
+
+// setOrderCurrency проставляет валюту заказа во все суммы после чтения из БД
+func setOrderCurrency(order *domain.Order) {
+	order.TotalAmount = order.TotalAmount.In(order.Currency)
+	order.Pricing.Subtotal = order.Pricing.Subtotal.In(order.Currency)
+	order.Pricing.Discount = order.Pricing.Discount.In(order.Currency)
+	order.Pricing.ServiceCharge = order.Pricing.ServiceCharge.In(order.Currency)
+	order.Pricing.DeliveryFee = order.Pricing.DeliveryFee.In(order.Currency)
+	order.Pricing.Total = order.Pricing.Total.In(order.Currency)
+	for i := range order.Pricing.Taxes {
+		order.Pricing.Taxes[i].Amount = order.Pricing.Taxes[i].Amount.In(order.Currency)
+	}
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.Price = item.Price.In(order.Currency)
+		for j := range item.Modifiers {
+			item.Modifiers[j].Price = item.Modifiers[j].Price.In(order.Currency)
+		}
+	}
+}
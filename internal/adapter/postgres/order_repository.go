@@ -2,11 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/domain"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+	"github.com/jackc/pgx/v5"
 )
 
 type orderRepository struct {
@@ -17,7 +20,8 @@ func NewOrderRepository(db DB) interfaces.OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error {
+// Create сохраняет заказ вместе с событием outbox в одной транзакции
+func (r *orderRepository) Create(ctx context.Context, order *domain.Order, event *domain.OutboxEvent) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -27,32 +31,65 @@ func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error
 	// Insert order
 	query := `
 		INSERT INTO orders (number, customer_name, type, table_number, delivery_address, 
-		                    total_amount, priority, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		                    subtotal, discount, promo_code, service_charge, delivery_fee, total_amount, currency,
+		                    priority, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id
 	`
 	err = tx.QueryRow(ctx, query,
 		order.Number, order.CustomerName, order.Type, order.TableNumber, order.DeliveryAddress,
-		order.TotalAmount, order.Priority, order.Status, order.CreatedAt, order.UpdatedAt,
+		moneyArg(order.Pricing.Subtotal), moneyArg(order.Pricing.Discount), order.Pricing.PromoCode,
+		moneyArg(order.Pricing.ServiceCharge), moneyArg(order.Pricing.DeliveryFee),
+		moneyArg(order.TotalAmount), order.Currency, order.Priority, order.Status, order.CreatedAt, order.UpdatedAt,
 	).Scan(&order.ID)
 	if err != nil {
 		return fmt.Errorf("failed to insert order: %w", err)
 	}
 
+	// Redeem promo code: usage limits are checked in the same transaction
+	if order.Discount.PromotionID != 0 {
+		if err := redeemPromotion(ctx, tx, order); err != nil {
+			return err
+		}
+	}
+
+	// Insert tax lines
+	for _, line := range order.Pricing.Taxes {
+		taxQuery := `
+			INSERT INTO order_tax_lines (order_id, name, rate_bps, amount)
+			VALUES ($1, $2, $3, $4)
+		`
+		_, err = tx.Exec(ctx, taxQuery, order.ID, line.Name, line.RateBps, moneyArg(line.Amount))
+		if err != nil {
+			return fmt.Errorf("failed to insert order tax line: %w", err)
+		}
+	}
+
 	// Insert order items
 	for i := range order.Items {
 		itemQuery := `
-			INSERT INTO order_items (order_id, name, quantity, price, created_at)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO order_items (order_id, menu_item_id, name, quantity, price, created_at)
+			VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6)
 			RETURNING id
 		`
 		err = tx.QueryRow(ctx, itemQuery,
-			order.ID, order.Items[i].Name, order.Items[i].Quantity, order.Items[i].Price, time.Now(),
+			order.ID, order.Items[i].MenuItemID, order.Items[i].Name, order.Items[i].Quantity, moneyArg(order.Items[i].Price), time.Now(),
 		).Scan(&order.Items[i].ID)
 		if err != nil {
 			return fmt.Errorf("failed to insert order item: %w", err)
 		}
 		order.Items[i].OrderID = order.ID
+
+		modQuery := `
+			INSERT INTO order_item_modifiers (order_item_id, modifier_id, group_name, name, price, created_at)
+			VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6)
+		`
+		for _, mod := range order.Items[i].Modifiers {
+			_, err = tx.Exec(ctx, modQuery, order.Items[i].ID, mod.ModifierID, mod.Group, mod.Name, moneyArg(mod.Price), time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to insert order item modifier: %w", err)
+			}
+		}
 	}
 
 	// Log initial status
@@ -65,29 +102,45 @@ func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error
 		return fmt.Errorf("failed to log status: %w", err)
 	}
 
+	// Outbox event
+	if event != nil {
+		outboxQuery := `
+			INSERT INTO order_outbox (order_id, event_type, payload, request_id, traceparent, created_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+			RETURNING id
+		`
+		event.OrderID = order.ID
+		event.CreatedAt = time.Now()
+		err = tx.QueryRow(ctx, outboxQuery, event.OrderID, event.EventType, event.Payload, event.RequestID, event.Traceparent, event.CreatedAt).Scan(&event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert outbox event: %w", err)
+		}
+	}
+
 	return tx.Commit(ctx)
 }
 
 func (r *orderRepository) FindByNumber(ctx context.Context, number string) (*domain.Order, error) {
 	query := `
-		SELECT id, number, customer_name, type, table_number, delivery_address,
-		       total_amount, priority, status, processed_by, created_at, updated_at, completed_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE number = $1
 	`
 
-	var order domain.Order
-	err := r.db.QueryRow(ctx, query, number).Scan(
-		&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber,
-		&order.DeliveryAddress, &order.TotalAmount, &order.Priority, &order.Status,
-		&order.ProcessedBy, &order.CreatedAt, &order.UpdatedAt, &order.CompletedAt,
-	)
+	order, err := scanOrder(r.db.QueryRow(ctx, query, number))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrOrderNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("order not found: %w", err)
 	}
 
 	// Load order items
-	itemsQuery := `SELECT id, order_id, name, quantity, price FROM order_items WHERE order_id = $1`
+	itemsQuery := `
+		SELECT id, order_id, COALESCE(menu_item_id, 0), name, quantity, price
+		FROM order_items
+		WHERE order_id = $1
+	`
 	rows, err := r.db.Query(ctx, itemsQuery, order.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load order items: %w", err)
@@ -96,34 +149,99 @@ func (r *orderRepository) FindByNumber(ctx context.Context, number string) (*dom
 
 	for rows.Next() {
 		var item domain.OrderItem
-		if err := rows.Scan(&item.ID, &item.OrderID, &item.Name, &item.Quantity, &item.Price); err != nil {
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.MenuItemID, &item.Name, &item.Quantity, scanMoney(&item.Price)); err != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", err)
 		}
 		order.Items = append(order.Items, item)
 	}
+	rows.Close()
 
-	return &order, nil
+	if err := r.loadItemModifiers(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := r.loadTaxLines(ctx, order); err != nil {
+		return nil, err
+	}
+	setOrderCurrency(order)
+
+	return order, nil
+}
+
+// loadTaxLines загружает налоги из расчета стоимости заказа
+func (r *orderRepository) loadTaxLines(ctx context.Context, order *domain.Order) error {
+	query := `
+		SELECT name, rate_bps, amount
+		FROM order_tax_lines
+		WHERE order_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load order tax lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.TaxLine
+		if err := rows.Scan(&line.Name, &line.RateBps, scanMoney(&line.Amount)); err != nil {
+			return fmt.Errorf("failed to scan order tax line: %w", err)
+		}
+		order.Pricing.Taxes = append(order.Pricing.Taxes, line)
+	}
+
+	return nil
+}
+
+// loadItemModifiers загружает модификаторы всех позиций заказа одним запросом
+func (r *orderRepository) loadItemModifiers(ctx context.Context, order *domain.Order) error {
+	query := `
+		SELECT m.order_item_id, COALESCE(m.modifier_id, 0), m.group_name, m.name, m.price
+		FROM order_item_modifiers m
+		JOIN order_items i ON i.id = m.order_item_id
+		WHERE i.order_id = $1
+		ORDER BY m.id
+	`
+
+	rows, err := r.db.Query(ctx, query, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load order item modifiers: %w", err)
+	}
+	defer rows.Close()
+
+	byItem := make(map[int]*domain.OrderItem, len(order.Items))
+	for i := range order.Items {
+		byItem[order.Items[i].ID] = &order.Items[i]
+	}
+
+	for rows.Next() {
+		var itemID int
+		var mod domain.OrderItemModifier
+		if err := rows.Scan(&itemID, &mod.ModifierID, &mod.Group, &mod.Name, scanMoney(&mod.Price)); err != nil {
+			return fmt.Errorf("failed to scan order item modifier: %w", err)
+		}
+		if item, ok := byItem[itemID]; ok {
+			item.Modifiers = append(item.Modifiers, mod)
+		}
+	}
+
+	return nil
 }
 
 func (r *orderRepository) FindByID(ctx context.Context, id int) (*domain.Order, error) {
 	query := `
-		SELECT id, number, customer_name, type, table_number, delivery_address,
-		       total_amount, priority, status, processed_by, created_at, updated_at, completed_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE id = $1
 	`
 
-	var order domain.Order
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber,
-		&order.DeliveryAddress, &order.TotalAmount, &order.Priority, &order.Status,
-		&order.ProcessedBy, &order.CreatedAt, &order.UpdatedAt, &order.CompletedAt,
-	)
+	order, err := scanOrder(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("order not found: %w", err)
 	}
+	setOrderCurrency(order)
 
-	return &order, nil
+	return order, nil
 }
 
 func (r *orderRepository) Update(ctx context.Context, order *domain.Order) error {
@@ -167,22 +285,23 @@ func (r *orderRepository) GetStatusHistory(ctx context.Context, orderID int) ([]
 	return logs, nil
 }
 
-func (r *orderRepository) GenerateOrderNumber(ctx context.Context) (string, error) {
-	now := time.Now().UTC()
-	prefix := fmt.Sprintf("ORD_%s_", now.Format("20060102"))
-
+// NextOrderSequence атомарно увеличивает счетчик заказов за день.
+// UPSERT блокирует строку счетчика, поэтому параллельные запросы получают разные значения.
+func (r *orderRepository) NextOrderSequence(ctx context.Context, day time.Time) (int64, error) {
 	query := `
-		SELECT COUNT(*) FROM orders 
-		WHERE number LIKE $1 AND DATE(created_at) = $2
+		INSERT INTO order_number_counters (day, last_value)
+		VALUES ($1, 1)
+		ON CONFLICT (day) DO UPDATE SET last_value = order_number_counters.last_value + 1
+		RETURNING last_value
 	`
 
-	var count int
-	err := r.db.QueryRow(ctx, query, prefix+"%", now.Format("2006-01-02")).Scan(&count)
+	var seq int64
+	err := r.db.QueryRow(ctx, query, day.UTC().Format("2006-01-02")).Scan(&seq)
 	if err != nil {
-		return "", fmt.Errorf("failed to count orders: %w", err)
+		return 0, fmt.Errorf("failed to increment order counter: %w", err)
 	}
 
-	return fmt.Sprintf("%s%03d", prefix, count+1), nil
+	return seq, nil
 }
 
 func (r *orderRepository) LogStatus(ctx context.Context, orderID int, status domain.Status, changedBy string) error {
@@ -197,24 +316,164 @@ func (r *orderRepository) LogStatus(ctx context.Context, orderID int, status dom
 	return nil
 }
 
-func (r *orderRepository) UpdateStatusWithLog(ctx context.Context, order *domain.Order, status domain.Status, changedBy string) error {
+func (r *orderRepository) UpdateStatusWithLog(ctx context.Context, order *domain.Order, status domain.Status, changedBy string, notes *string) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	query := `UPDATE orders SET status = $1, processed_by = $2, updated_at = $3, completed_at = $4 WHERE id = $5`
-	_, err = tx.Exec(ctx, query, order.Status, order.ProcessedBy, order.UpdatedAt, order.CompletedAt, order.ID)
+	// Блокируем строку и проверяем переход по актуальному статусу в БД,
+	// чтобы отмена и кухня не перезаписывали изменения друг друга
+	var current domain.Status
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, order.ID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	stored := domain.Order{Status: current}
+	if !stored.CanTransitionTo(status) {
+		return fmt.Errorf("%w: %s -> %s", domain.ErrInvalidStatusTransition, current, status)
+	}
+
+	query := `
+		UPDATE orders
+		SET status = $1, processed_by = COALESCE($2, processed_by), updated_at = $3,
+		    completed_at = COALESCE($4, completed_at), handed_over_at = COALESCE($5, handed_over_at)
+		WHERE id = $6
+	`
+	_, err = tx.Exec(ctx, query, order.Status, order.ProcessedBy, order.UpdatedAt, order.CompletedAt, order.HandedOverAt, order.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update order: %w", err)
 	}
 
-	logQuery := `INSERT INTO order_status_log (order_id, status, changed_by, changed_at) VALUES ($1, $2, $3, $4)`
-	_, err = tx.Exec(ctx, logQuery, order.ID, status, changedBy, time.Now())
+	logQuery := `INSERT INTO order_status_log (order_id, status, changed_by, changed_at, notes) VALUES ($1, $2, $3, $4, $5)`
+	_, err = tx.Exec(ctx, logQuery, order.ID, status, changedBy, time.Now(), notes)
 	if err != nil {
 		return fmt.Errorf("failed to log status: %w", err)
 	}
 
+	if status == domain.StatusCancelled {
+		if err := releasePromotion(ctx, tx, order.ID); err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit(ctx)
 }
+
+// List ищет заказы по фильтру. Пагинация keyset по (поле сортировки, id),
+// поэтому запросы по status и created_at используют idx_orders_status и idx_orders_created_at.
+func (r *orderRepository) List(ctx context.Context, filter interfaces.OrderFilter) ([]*domain.Order, error) {
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, st := range filter.Statuses {
+			statuses[i] = string(st)
+		}
+		conds = append(conds, "status = ANY("+arg(statuses)+")")
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			types[i] = string(t)
+		}
+		conds = append(conds, "type = ANY("+arg(types)+")")
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "created_at < "+arg(*filter.CreatedTo))
+	}
+	if filter.CustomerName != "" {
+		conds = append(conds, "customer_name ILIKE "+arg("%"+escapeLike(filter.CustomerName)+"%"))
+	}
+	if filter.ProcessedBy != "" {
+		conds = append(conds, "processed_by = "+arg(filter.ProcessedBy))
+	}
+	if filter.Priority != nil {
+		conds = append(conds, "priority = "+arg(*filter.Priority))
+	}
+
+	sortColumn, sortCast := "created_at", "timestamptz"
+	switch filter.SortBy {
+	case interfaces.OrderSortUpdatedAt:
+		sortColumn, sortCast = "updated_at", "timestamptz"
+	case interfaces.OrderSortPriority:
+		sortColumn, sortCast = "priority", "integer"
+	}
+
+	direction, cmp := "ASC", ">"
+	if filter.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		conds = append(conds, fmt.Sprintf("(%s, id) %s (%s::%s, %s)",
+			sortColumn, cmp, arg(filter.After.SortValue), sortCast, arg(filter.After.ID)))
+	}
+
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+	`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", sortColumn, direction, direction, arg(filter.Limit))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		setOrderCurrency(order)
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+const orderColumns = `id, number, customer_name, type, table_number, delivery_address,
+		       subtotal, discount, COALESCE(promo_code, ''), service_charge, delivery_fee, total_amount, currency,
+		       priority, status, processed_by, created_at, updated_at, completed_at, handed_over_at`
+
+// scanOrder читает строку orders; налоги, позиции и валюта сумм загружаются отдельно
+func scanOrder(row Row) (*domain.Order, error) {
+	var order domain.Order
+	err := row.Scan(
+		&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber, &order.DeliveryAddress,
+		scanMoney(&order.Pricing.Subtotal), scanMoney(&order.Pricing.Discount), &order.Pricing.PromoCode,
+		scanMoney(&order.Pricing.ServiceCharge), scanMoney(&order.Pricing.DeliveryFee),
+		scanMoney(&order.TotalAmount), &order.Currency,
+		&order.Priority, &order.Status, &order.ProcessedBy, &order.CreatedAt, &order.UpdatedAt, &order.CompletedAt, &order.HandedOverAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	order.Pricing.Total = order.TotalAmount
+	order.Discount.Code = order.Pricing.PromoCode
+	return &order, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE во вводе пользователя
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
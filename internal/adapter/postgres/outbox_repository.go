@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+type outboxRepository struct {
+	db DB
+}
+
+func NewOutboxRepository(db DB) interfaces.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// ClaimPending захватывает готовые к отправке события на время lease.
+// SKIP LOCKED позволяет нескольким экземплярам order-service не отправлять одно и то же событие одновременно.
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+	query := `
+		UPDATE order_outbox
+		SET locked_until = $1
+		WHERE id IN (
+			SELECT id FROM order_outbox
+			WHERE status = 'pending'
+			  AND next_attempt_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, order_id, event_type, payload, COALESCE(request_id, ''), COALESCE(traceparent, ''), attempts, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, time.Now().Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.OutboxEvent
+	for rows.Next() {
+		var event domain.OutboxEvent
+		if err := rows.Scan(&event.ID, &event.OrderID, &event.EventType, &event.Payload, &event.RequestID, &event.Traceparent, &event.Attempts, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, id int64) error {
+	query := `
+		UPDATE order_outbox
+		SET status = 'sent', sent_at = $1, attempts = attempts + 1, locked_until = NULL, last_error = NULL
+		WHERE id = $2
+	`
+	_, err := r.db.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event sent: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, reason string, nextAttempt time.Time) error {
+	query := `
+		UPDATE order_outbox
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2, locked_until = NULL
+		WHERE id = $3
+	`
+	_, err := r.db.Exec(ctx, query, reason, nextAttempt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
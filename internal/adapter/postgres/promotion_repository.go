@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type promotionRepository struct {
+	db DB
+}
+
+func NewPromotionRepository(db DB) interfaces.PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+const promotionColumns = `id, code, description, kind, percent_bps, amount, COALESCE(menu_item_id, 0),
+		buy_quantity, free_quantity, min_subtotal, valid_from, valid_to, max_uses, max_uses_per_customer,
+		times_used, active, created_at`
+
+func (r *promotionRepository) FindByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1`
+
+	promotion, err := scanPromotion(r.db.QueryRow(ctx, query, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPromotionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (r *promotionRepository) List(ctx context.Context) ([]*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var promotions []*domain.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, nil
+}
+
+func (r *promotionRepository) Create(ctx context.Context, p *domain.Promotion) error {
+	query := `
+		INSERT INTO promotions (code, description, kind, percent_bps, amount, menu_item_id,
+		                        buy_quantity, free_quantity, min_subtotal, valid_from, valid_to,
+		                        max_uses, max_uses_per_customer, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`
+	err := r.db.QueryRow(ctx, query,
+		p.Code, p.Description, p.Kind, p.PercentBps, moneyArg(p.Amount), p.MenuItemID,
+		p.BuyQuantity, p.FreeQuantity, moneyArg(p.MinSubtotal), p.ValidFrom, p.ValidTo,
+		p.MaxUses, p.MaxUsesPerCustomer, p.Active, p.CreatedAt,
+	).Scan(&p.ID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrPromotionCodeExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+	return nil
+}
+
+func (r *promotionRepository) Deactivate(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE promotions SET active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate promotion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPromotionNotFound
+	}
+	return nil
+}
+
+func scanPromotion(row Row) (*domain.Promotion, error) {
+	var p domain.Promotion
+	err := row.Scan(
+		&p.ID, &p.Code, &p.Description, &p.Kind, &p.PercentBps, scanMoney(&p.Amount), &p.MenuItemID,
+		&p.BuyQuantity, &p.FreeQuantity, scanMoney(&p.MinSubtotal), &p.ValidFrom, &p.ValidTo,
+		&p.MaxUses, &p.MaxUsesPerCustomer, &p.TimesUsed, &p.Active, &p.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan promotion: %w", err)
+	}
+	return &p, nil
+}
+
+// redeemPromotion фиксирует использование промокода в транзакции создания заказа.
+// UPDATE блокирует строку промокода, поэтому параллельные заказы с одним кодом
+// проверяют лимиты по очереди и не могут превысить их.
+func redeemPromotion(ctx context.Context, tx Tx, order *domain.Order) error {
+	var maxPerCustomer *int
+	err := tx.QueryRow(ctx, `
+		UPDATE promotions
+		SET times_used = times_used + 1
+		WHERE id = $1 AND active AND (max_uses IS NULL OR times_used < max_uses)
+		RETURNING max_uses_per_customer
+	`, order.Discount.PromotionID).Scan(&maxPerCustomer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrPromotionLimitReached
+	}
+	if err != nil {
+		return fmt.Errorf("failed to redeem promotion: %w", err)
+	}
+
+	customerKey := order.Discount.CustomerKey
+
+	if maxPerCustomer != nil {
+		var used int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM promotion_redemptions
+			WHERE promotion_id = $1 AND customer_key = $2
+		`, order.Discount.PromotionID, customerKey).Scan(&used)
+		if err != nil {
+			return fmt.Errorf("failed to count promotion redemptions: %w", err)
+		}
+		if used >= *maxPerCustomer {
+			return domain.ErrPromotionLimitReached
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO promotion_redemptions (promotion_id, order_id, customer_key, discount_amount, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, order.Discount.PromotionID, order.ID, customerKey, moneyArg(order.Pricing.Discount))
+	if err != nil {
+		return fmt.Errorf("failed to insert promotion redemption: %w", err)
+	}
+
+	return nil
+}
+
+// releasePromotion возвращает использование промокода отмененного заказа в транзакции отмены,
+// чтобы отмена не расходовала лимиты max_uses и max_uses_per_customer
+func releasePromotion(ctx context.Context, tx Tx, orderID int) error {
+	_, err := tx.Exec(ctx, `
+		WITH released AS (
+			DELETE FROM promotion_redemptions WHERE order_id = $1 RETURNING promotion_id
+		)
+		UPDATE promotions p
+		SET times_used = GREATEST(p.times_used - r.n, 0)
+		FROM (SELECT promotion_id, COUNT(*) AS n FROM released GROUP BY promotion_id) r
+		WHERE p.id = r.promotion_id
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to release promotion: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxTracedStatementLen = 200
+
+// tracedDB оборачивает DB и открывает спан на каждый запрос внутри трейса.
+// Спан запроса закрывается после чтения результата: Rows.Close или Row.Scan.
+type tracedDB struct {
+	db DB
+}
+
+func NewTracedDB(db DB) DB {
+	return &tracedDB{db: db}
+}
+
+type tracedTx struct {
+	tx   Tx
+	span *tracing.Span
+	done bool
+}
+
+type tracedRows struct {
+	Rows
+	span *tracing.Span
+}
+
+type tracedRow struct {
+	row  Row
+	span *tracing.Span
+}
+
+func startQuerySpan(ctx context.Context, operation, sql string) (context.Context, *tracing.Span) {
+	ctx, span := tracing.StartChild(ctx, "postgres."+operation, tracing.KindClient)
+	span.SetAttr("db.system", "postgresql")
+	span.SetAttr("db.statement", compactStatement(sql))
+	return ctx, span
+}
+
+// compactStatement схлопывает пробелы и обрезает SQL, чтобы спаны оставались компактными
+func compactStatement(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxTracedStatementLen {
+		sql = sql[:maxTracedStatementLen] + "..."
+	}
+	return sql
+}
+
+func (db *tracedDB) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	ctx, span := startQuerySpan(ctx, "query", sql)
+	rows, err := db.db.Query(ctx, sql, args...)
+	if err != nil {
+		span.SetError(err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (db *tracedDB) QueryRow(ctx context.Context, sql string, args ...any) Row {
+	ctx, span := startQuerySpan(ctx, "query_row", sql)
+	return &tracedRow{row: db.db.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (db *tracedDB) Exec(ctx context.Context, sql string, args ...any) (CommandTag, error) {
+	ctx, span := startQuerySpan(ctx, "exec", sql)
+	defer span.End()
+	tag, err := db.db.Exec(ctx, sql, args...)
+	span.SetError(err)
+	return tag, err
+}
+
+func (db *tracedDB) Begin(ctx context.Context) (Tx, error) {
+	ctx, span := tracing.StartChild(ctx, "postgres.transaction", tracing.KindClient)
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		span.SetError(err)
+		span.End()
+		return nil, err
+	}
+	return &tracedTx{tx: tx, span: span}, nil
+}
+
+func (db *tracedDB) Ping(ctx context.Context) error {
+	return db.db.Ping(ctx)
+}
+
+func (db *tracedDB) Stats() PoolStats {
+	return db.db.Stats()
+}
+
+func (db *tracedDB) Close() {
+	db.db.Close()
+}
+
+func (t *tracedTx) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	ctx, span := startQuerySpan(ctx, "query", sql)
+	rows, err := t.tx.Query(ctx, sql, args...)
+	if err != nil {
+		span.SetError(err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (t *tracedTx) QueryRow(ctx context.Context, sql string, args ...any) Row {
+	ctx, span := startQuerySpan(ctx, "query_row", sql)
+	return &tracedRow{row: t.tx.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (t *tracedTx) Exec(ctx context.Context, sql string, args ...any) (CommandTag, error) {
+	ctx, span := startQuerySpan(ctx, "exec", sql)
+	defer span.End()
+	tag, err := t.tx.Exec(ctx, sql, args...)
+	span.SetError(err)
+	return tag, err
+}
+
+func (t *tracedTx) Commit(ctx context.Context) error {
+	err := t.tx.Commit(ctx)
+	t.finish("commit", err)
+	return err
+}
+
+// Rollback после Commit (defer tx.Rollback) не меняет уже закрытый спан
+func (t *tracedTx) Rollback(ctx context.Context) error {
+	err := t.tx.Rollback(ctx)
+	t.finish("rollback", nil)
+	return err
+}
+
+func (t *tracedTx) finish(outcome string, err error) {
+	if t.done {
+		return
+	}
+	t.done = true
+	t.span.SetAttr("db.outcome", outcome)
+	t.span.SetError(err)
+	t.span.End()
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.span.End()
+}
+
+func (r *tracedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		r.span.SetError(err)
+	}
+	r.span.End()
+	return err
+}
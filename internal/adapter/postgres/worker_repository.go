@@ -19,12 +19,13 @@ func NewWorkerRepository(db DB) interfaces.WorkerRepository {
 
 func (r *workerRepository) Create(ctx context.Context, worker *domain.Worker) error {
 	query := `
-		INSERT INTO workers (name, type, status, last_seen, orders_processed, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO workers (name, type, status, last_seen, orders_processed, capacity, current_load, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 	err := r.db.QueryRow(ctx, query,
-		worker.Name, worker.Type, worker.Status, worker.LastSeen, worker.OrdersProcessed, worker.CreatedAt,
+		worker.Name, worker.Type, worker.Status, worker.LastSeen, worker.OrdersProcessed,
+		worker.Capacity, worker.CurrentLoad, worker.CreatedAt,
 	).Scan(&worker.ID)
 	if err != nil {
 		return fmt.Errorf("failed to create worker: %w", err)
@@ -34,7 +35,7 @@ func (r *workerRepository) Create(ctx context.Context, worker *domain.Worker) er
 
 func (r *workerRepository) FindByName(ctx context.Context, name string) (*domain.Worker, error) {
 	query := `
-		SELECT id, name, type, status, last_seen, orders_processed, created_at
+		SELECT id, name, type, status, last_seen, orders_processed, capacity, current_load, created_at
 		FROM workers
 		WHERE name = $1
 	`
@@ -42,7 +43,7 @@ func (r *workerRepository) FindByName(ctx context.Context, name string) (*domain
 	var worker domain.Worker
 	err := r.db.QueryRow(ctx, query, name).Scan(
 		&worker.ID, &worker.Name, &worker.Type, &worker.Status,
-		&worker.LastSeen, &worker.OrdersProcessed, &worker.CreatedAt,
+		&worker.LastSeen, &worker.OrdersProcessed, &worker.Capacity, &worker.CurrentLoad, &worker.CreatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("worker not found: %w", err)
@@ -54,11 +55,11 @@ func (r *workerRepository) FindByName(ctx context.Context, name string) (*domain
 func (r *workerRepository) Update(ctx context.Context, worker *domain.Worker) error {
 	query := `
 		UPDATE workers
-		SET type = $1, status = $2, last_seen = $3, orders_processed = $4
-		WHERE id = $5
+		SET type = $1, status = $2, last_seen = $3, orders_processed = $4, capacity = $5, current_load = $6
+		WHERE id = $7
 	`
 	_, err := r.db.Exec(ctx, query,
-		worker.Type, worker.Status, worker.LastSeen, worker.OrdersProcessed, worker.ID,
+		worker.Type, worker.Status, worker.LastSeen, worker.OrdersProcessed, worker.Capacity, worker.CurrentLoad, worker.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update worker: %w", err)
@@ -79,9 +80,23 @@ func (r *workerRepository) UpdateHeartbeat(ctx context.Context, name string) err
 	return nil
 }
 
+// UpdateLoad записывает число занятых слотов воркера
+func (r *workerRepository) UpdateLoad(ctx context.Context, name string, load int) error {
+	query := `
+		UPDATE workers
+		SET current_load = $1
+		WHERE name = $2
+	`
+	_, err := r.db.Exec(ctx, query, load, name)
+	if err != nil {
+		return fmt.Errorf("failed to update worker load: %w", err)
+	}
+	return nil
+}
+
 func (r *workerRepository) ListAll(ctx context.Context) ([]*domain.Worker, error) {
 	query := `
-		SELECT id, name, type, status, last_seen, orders_processed, created_at
+		SELECT id, name, type, status, last_seen, orders_processed, capacity, current_load, created_at
 		FROM workers
 		ORDER BY name
 	`
@@ -97,7 +112,7 @@ func (r *workerRepository) ListAll(ctx context.Context) ([]*domain.Worker, error
 		var worker domain.Worker
 		if err := rows.Scan(
 			&worker.ID, &worker.Name, &worker.Type, &worker.Status,
-			&worker.LastSeen, &worker.OrdersProcessed, &worker.CreatedAt,
+			&worker.LastSeen, &worker.OrdersProcessed, &worker.Capacity, &worker.CurrentLoad, &worker.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan worker: %w", err)
 		}
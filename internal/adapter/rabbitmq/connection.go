@@ -18,7 +18,11 @@ type Connection interface {
 type Channel interface {
 	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
 	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (Queue, error)
+	QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (Queue, error)
 	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	QueueUnbind(name, key, exchange string, args amqp.Table) error
+	QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error)
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
 	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
 	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
 	Qos(prefetchCount, prefetchSize int, global bool) error
@@ -135,10 +139,30 @@ func (ch *amqpChannel) QueueDeclare(name string, durable, autoDelete, exclusive,
 	return Queue{Name: q.Name, Messages: q.Messages, Consumers: q.Consumers}, nil
 }
 
+func (ch *amqpChannel) QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (Queue, error) {
+	q, err := ch.ch.QueueDeclarePassive(name, durable, autoDelete, exclusive, noWait, args)
+	if err != nil {
+		return Queue{}, err
+	}
+	return Queue{Name: q.Name, Messages: q.Messages, Consumers: q.Consumers}, nil
+}
+
 func (ch *amqpChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
 	return ch.ch.QueueBind(name, key, exchange, noWait, args)
 }
 
+func (ch *amqpChannel) QueueUnbind(name, key, exchange string, args amqp.Table) error {
+	return ch.ch.QueueUnbind(name, key, exchange, args)
+}
+
+func (ch *amqpChannel) QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error) {
+	return ch.ch.QueueDelete(name, ifUnused, ifEmpty, noWait)
+}
+
+func (ch *amqpChannel) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	return ch.ch.Get(queue, autoAck)
+}
+
 func (ch *amqpChannel) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
 	return ch.ch.Publish(exchange, key, mandatory, immediate, msg)
 }
@@ -2,25 +2,62 @@ package rabbitmq
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type consumer struct {
 	conn     Connection
+	logger   logger.Logger
 	prefetch int
+	// concurrency - сколько заказов обрабатывается одновременно (слоты печи воркера)
+	concurrency int
+	// maxPriority - уровни приоритета очередей кухни, 0 - без приоритетов
+	maxPriority int
+	// orderTypes - типы заказов воркера, пустой список - general-воркер, читающий все очереди
+	orderTypes []domain.OrderType
+	retry      retryPolicy
+
+	// Число запущенных циклов получения и тех из них, что сейчас подписаны на очередь
+	loops      atomic.Int32
+	subscribed atomic.Int32
 }
 
-func NewConsumer(conn Connection, prefetch int) interfaces.MessageConsumer {
-	return &consumer{conn: conn, prefetch: prefetch}
+// NewConsumer создает consumer, обрабатывающий до concurrency заказов параллельно из очередей типов orderTypes
+// (nil - из всех очередей кухни). prefetch не может быть меньше concurrency, иначе часть слотов будет простаивать.
+func NewConsumer(conn Connection, cfg config.RabbitMQConfig, logger logger.Logger, prefetch, concurrency int, orderTypes []domain.OrderType) interfaces.MessageConsumer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if prefetch < concurrency {
+		prefetch = concurrency
+	}
+	return &consumer{
+		conn:        conn,
+		logger:      logger,
+		prefetch:    prefetch,
+		concurrency: concurrency,
+		maxPriority: cfg.MaxPriority,
+		orderTypes:  orderTypes,
+		retry:       newRetryPolicy(cfg),
+	}
 }
 
 func (c *consumer) ConsumeOrders(ctx context.Context, handler interfaces.OrderMessageHandler) error {
+	c.loops.Add(1)
+	defer c.loops.Add(-1)
+
 	for {
 		err := c.consumeOrdersWithReconnect(ctx, handler)
 
@@ -34,7 +71,7 @@ func (c *consumer) ConsumeOrders(ctx context.Context, handler interfaces.OrderMe
 		}
 
 		// Логируем ошибку и пытаемся переподключиться
-		log.Printf("Orders consumer disconnected: %v. Reconnecting in 5 seconds...", err)
+		c.logger.Error(ctx, "rabbitmq_consumer_disconnected", "Orders consumer disconnected, reconnecting in 5 seconds", nil, err)
 
 		select {
 		case <-ctx.Done():
@@ -46,6 +83,9 @@ func (c *consumer) ConsumeOrders(ctx context.Context, handler interfaces.OrderMe
 }
 
 func (c *consumer) ConsumeNotifications(ctx context.Context, handler interfaces.NotificationHandler) error {
+	c.loops.Add(1)
+	defer c.loops.Add(-1)
+
 	for {
 		err := c.consumeNotificationsWithReconnect(ctx, handler)
 
@@ -59,7 +99,7 @@ func (c *consumer) ConsumeNotifications(ctx context.Context, handler interfaces.
 		}
 
 		// Логируем ошибку и пытаемся переподключиться
-		log.Printf("Notifications consumer disconnected: %v. Reconnecting in 5 seconds...", err)
+		c.logger.Error(ctx, "rabbitmq_consumer_disconnected", "Notifications consumer disconnected, reconnecting in 5 seconds", nil, err)
 
 		select {
 		case <-ctx.Done():
@@ -70,7 +110,55 @@ func (c *consumer) ConsumeNotifications(ctx context.Context, handler interfaces.
 	}
 }
 
+func (c *consumer) Consuming() bool {
+	loops := c.loops.Load()
+	return loops > 0 && c.subscribed.Load() == loops
+}
+
+// queuedDelivery - сообщение вместе с очередью, из которой оно получено
+type queuedDelivery struct {
+	queue string
+	msg   amqp.Delivery
+}
+
+// orderQueues возвращает очереди, которые читает воркер: очереди его типов заказов
+// или все очереди кухни для general-воркера, включая не разобранную после миграции общую очередь
+func (c *consumer) orderQueues(topology ordersTopology) []string {
+	if len(c.orderTypes) == 0 {
+		queues := KitchenQueues()
+		if topology.legacyQueue {
+			queues = append(queues, legacyKitchenQueue)
+		}
+		return queues
+	}
+
+	queues := make([]string, len(c.orderTypes))
+	for i, t := range c.orderTypes {
+		queues[i] = KitchenQueue(t)
+	}
+	return queues
+}
+
 func (c *consumer) consumeOrdersWithReconnect(ctx context.Context, handler interfaces.OrderMessageHandler) error {
+	// Declare exchanges and queues
+	topology, err := declareOrdersTopology(c.conn, c.maxPriority)
+	if err != nil {
+		return err
+	}
+	for _, queue := range topology.mismatched {
+		c.logger.Info(ctx, "kitchen_queue_mismatched",
+			fmt.Sprintf("%s was declared with other arguments than x-max-priority=%d and the DLQ routing key, priorities and the DLQ may not apply until --mode=migrate-kitchen-queues is run", queue, c.maxPriority),
+			map[string]interface{}{"queue": queue})
+	}
+	if topology.legacyQueue {
+		c.logger.Info(ctx, "kitchen_queue_legacy",
+			fmt.Sprintf("%s is no longer routed to, remaining orders are taken by general workers until --mode=migrate-kitchen-queues is run", legacyKitchenQueue),
+			map[string]interface{}{"queue": legacyKitchenQueue})
+	}
+	if err := declareRetryTopology(c.conn, c.retry); err != nil {
+		return err
+	}
+
 	ch, err := c.conn.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open channel: %w", err)
@@ -80,54 +168,157 @@ func (c *consumer) consumeOrdersWithReconnect(ctx context.Context, handler inter
 	// Отслеживаем закрытие канала
 	closeChan := ch.NotifyClose()
 
-	// Set QoS
-	if err := ch.Qos(c.prefetch, 0, false); err != nil {
+	// Set QoS: лимит общий для всех очередей канала
+	if err := ch.Qos(c.prefetch, 0, true); err != nil {
 		return fmt.Errorf("failed to set QoS: %w", err)
 	}
 
-	// Declare exchanges and queues
-	if err := c.setupOrdersInfrastructure(ch); err != nil {
-		return err
-	}
+	// Сообщения всех очередей сводятся в один поток; forwarders завершаются при выходе из функции
+	deliveries := make(chan queuedDelivery)
+	consumeErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
 
-	// Start consuming
-	msgs, err := ch.Consume("kitchen_queue", "", false, false, false, false, nil)
-	if err != nil {
-		return fmt.Errorf("failed to start consuming: %w", err)
+	for _, queue := range c.orderQueues(topology) {
+		msgs, err := ch.Consume(queue, "", false, false, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start consuming %s: %w", queue, err)
+		}
+
+		go func() {
+			for msg := range msgs {
+				select {
+				case deliveries <- queuedDelivery{queue: queue, msg: msg}:
+				case <-done:
+					return
+				}
+			}
+			select {
+			case consumeErr <- fmt.Errorf("messages channel of %s closed", queue):
+			default:
+			}
+		}()
 	}
+	c.subscribed.Add(1)
+	defer c.subscribed.Add(-1)
+
+	// Отмена ctx только останавливает прием: начатые заказы дорабатываются и подтверждаются,
+	// а неразобранные сообщения из prefetch вернутся в очередь при закрытии канала
+	handlerCtx := context.WithoutCancel(ctx)
+
+	// Каждое сообщение обрабатывается в своем слоте и подтверждается независимо.
+	// Ожидание обработчиков отложено после ch.Close, поэтому выполнится раньше него.
+	slots := make(chan struct{}, c.concurrency)
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
 
 	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Следующее сообщение берем только при свободном слоте
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 
 		case err := <-closeChan:
-			if err != nil {
-				return fmt.Errorf("channel closed: %w", err)
-			}
-			return fmt.Errorf("channel closed gracefully")
+			return channelClosedError(err)
 
-		case msg, ok := <-msgs:
-			if !ok {
-				return fmt.Errorf("messages channel closed")
-			}
+		case slots <- struct{}{}:
+		}
 
-			if err := handler(ctx, msg.Body); err != nil {
-				// Проверяем, является ли ошибка связанной со специализацией
-				if strings.Contains(err.Error(), "cannot handle order type") {
-					// Requeue для других воркеров
-					msg.Nack(false, true)
-				} else {
-					// Отправляем в DLQ (requeue=false)
-					msg.Nack(false, false)
-				}
-			} else {
-				msg.Ack(false)
-			}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-closeChan:
+			return channelClosedError(err)
+
+		case err := <-consumeErr:
+			return err
+
+		case d := <-deliveries:
+			inFlight.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					inFlight.Done()
+				}()
+				msgCtx := deliveryContext(handlerCtx, d.msg)
+				c.settleOrder(msgCtx, ch, d.queue, d.msg, handleDelivery(msgCtx, d.queue, d.msg, handler))
+			}()
 		}
 	}
 }
 
+// settleOrder подтверждает обработанный заказ, возвращает его в очередь, откладывает повтор
+// или отправляет в DLQ по ошибке обработчика. ctx - контекст сообщения с идентификатором запроса для логов.
+func (c *consumer) settleOrder(ctx context.Context, ch Channel, queue string, msg amqp.Delivery, err error) {
+	if err == nil {
+		msg.Ack(false)
+		metrics.ConsumedMessages.Inc(queue, "ack")
+		return
+	}
+
+	// Заказ возвращен остановленным воркером или попал не к своему воркеру (общая очередь до миграции)
+	if errors.Is(err, domain.ErrWorkerDraining) || errors.Is(err, domain.ErrUnsupportedOrderType) {
+		// Requeue для других воркеров
+		msg.Nack(false, true)
+		metrics.ConsumedMessages.Inc(queue, "requeue")
+		return
+	}
+
+	// Битое сообщение или несуществующий заказ повтор не исправит
+	if isPermanent(err) {
+		c.logger.Error(ctx, "order_message_rejected", fmt.Sprintf("Order message from %s rejected", queue), map[string]interface{}{
+			"queue": queue,
+		}, err)
+		msg.Nack(false, false)
+		metrics.ConsumedMessages.Inc(queue, "nack")
+		return
+	}
+
+	attempt := retryCount(msg.Headers) + 1
+	delay, ok := c.retry.delay(attempt)
+	if !ok {
+		c.logger.Error(ctx, "order_message_dead_lettered", fmt.Sprintf("Order message from %s failed after %d attempts, moving to DLQ", queue, attempt), map[string]interface{}{
+			"queue":    queue,
+			"attempts": attempt,
+		}, err)
+		// Отправляем в DLQ (requeue=false)
+		msg.Nack(false, false)
+		metrics.ConsumedMessages.Inc(queue, "nack")
+		return
+	}
+
+	// Временный сбой: заказ переждет в очереди задержки и вернется в свою очередь
+	if pubErr := scheduleRetry(ch, msg, delay); pubErr != nil {
+		c.logger.Error(ctx, "order_retry_failed", fmt.Sprintf("Failed to schedule retry of order message from %s", queue), map[string]interface{}{
+			"queue":   queue,
+			"attempt": attempt,
+		}, pubErr)
+		msg.Nack(false, true)
+		metrics.ConsumedMessages.Inc(queue, "requeue")
+		return
+	}
+	c.logger.Info(ctx, "order_retry_scheduled", fmt.Sprintf("Order message from %s will be retried in %s", queue, delay), map[string]interface{}{
+		"queue":    queue,
+		"attempt":  attempt,
+		"delay_ms": delay.Milliseconds(),
+		"error":    err.Error(),
+	})
+	msg.Ack(false)
+	metrics.ConsumedMessages.Inc(queue, "retry")
+}
+
+func channelClosedError(err *amqp.Error) error {
+	if err != nil {
+		return fmt.Errorf("channel closed: %w", err)
+	}
+	return fmt.Errorf("channel closed gracefully")
+}
+
 func (c *consumer) consumeNotificationsWithReconnect(ctx context.Context, handler interfaces.NotificationHandler) error {
 	ch, err := c.conn.Channel()
 	if err != nil {
@@ -159,6 +350,8 @@ func (c *consumer) consumeNotificationsWithReconnect(ctx context.Context, handle
 	if err != nil {
 		return fmt.Errorf("failed to start consuming: %w", err)
 	}
+	c.subscribed.Add(1)
+	defer c.subscribed.Add(-1)
 
 	for {
 		select {
@@ -166,59 +359,45 @@ func (c *consumer) consumeNotificationsWithReconnect(ctx context.Context, handle
 			return ctx.Err()
 
 		case err := <-closeChan:
-			if err != nil {
-				return fmt.Errorf("channel closed: %w", err)
-			}
-			return fmt.Errorf("channel closed gracefully")
+			return channelClosedError(err)
 
 		case msg, ok := <-msgs:
 			if !ok {
 				return fmt.Errorf("messages channel closed")
 			}
 
-			// Игнорируем ошибки обработки уведомлений
-			_ = handler(ctx, msg.Body)
+			// Игнорируем ошибки обработки уведомлений; сообщения подтверждаются автоматически
+			_ = handleDelivery(deliveryContext(ctx, msg), "notifications_fanout", msg, handler)
+			metrics.ConsumedMessages.Inc("notifications_fanout", "ack")
 		}
 	}
 }
 
-func (c *consumer) setupOrdersInfrastructure(ch Channel) error {
-	// Declare main exchange
-	if err := ch.ExchangeDeclare("orders_topic", "topic", true, false, false, false, nil); err != nil {
-		return fmt.Errorf("failed to declare orders exchange: %w", err)
-	}
-
-	// Declare DLQ exchange
-	dlqExchange := "orders_dlq"
-	if err := ch.ExchangeDeclare(dlqExchange, "direct", true, false, false, false, nil); err != nil {
-		return fmt.Errorf("failed to declare DLQ exchange: %w", err)
+// deliveryContext переносит в ctx контекст из заголовков сообщения: идентификатор запроса и родительский спан
+func deliveryContext(ctx context.Context, msg amqp.Delivery) context.Context {
+	if requestID, _ := msg.Headers[logger.RequestIDHeader].(string); logger.ValidRequestID(requestID) {
+		ctx = logger.WithRequestID(ctx, requestID)
 	}
-
-	// Declare DLQ queue
-	dlqQueue := "kitchen_queue_dlq"
-	if _, err := ch.QueueDeclare(dlqQueue, true, false, false, false, nil); err != nil {
-		return fmt.Errorf("failed to declare DLQ: %w", err)
-	}
-
-	// Bind DLQ
-	if err := ch.QueueBind(dlqQueue, "#", dlqExchange, false, nil); err != nil {
-		return fmt.Errorf("failed to bind DLQ: %w", err)
-	}
-
-	// Declare main queue with DLQ binding
-	args := amqp.Table{
-		"x-dead-letter-exchange": dlqExchange,
-	}
-
-	q, err := ch.QueueDeclare("kitchen_queue", true, false, false, false, args)
-	if err != nil {
-		return fmt.Errorf("failed to declare kitchen queue: %w", err)
+	if traceparent, _ := msg.Headers[tracing.TraceparentHeader].(string); traceparent != "" {
+		if parent, ok := tracing.ParseTraceparent(traceparent); ok {
+			ctx = tracing.WithRemoteParent(ctx, parent)
+		}
 	}
+	return ctx
+}
 
-	// Bind main queue
-	if err := ch.QueueBind(q.Name, "kitchen.#", "orders_topic", false, nil); err != nil {
-		return fmt.Errorf("failed to bind kitchen queue: %w", err)
+// handleDelivery вызывает обработчик с контекстом сообщения (deliveryContext) внутри спана получения;
+// время ожидания в очереди пишется в атрибуты спана
+func handleDelivery(ctx context.Context, queue string, msg amqp.Delivery, handler func(context.Context, []byte) error) error {
+	ctx, span := tracing.Start(ctx, "rabbitmq.consume "+queue, tracing.KindConsumer)
+	defer span.End()
+	span.SetAttr("messaging.source", queue)
+	span.SetAttr("messaging.redelivered", msg.Redelivered)
+	if !msg.Timestamp.IsZero() {
+		span.SetAttr("messaging.queue_wait_ms", time.Since(msg.Timestamp).Milliseconds())
 	}
 
-	return nil
+	err := handler(ctx, msg.Body)
+	span.SetError(err)
+	return err
 }
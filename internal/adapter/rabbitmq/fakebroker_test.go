@@ -0,0 +1,355 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeBroker - exchanges, очереди и маршрутизация в памяти с семантикой RabbitMQ, на которую опирается адаптер:
+//   - direct сравнивает ключ буквально, topic - по шаблону, headers - по заголовкам привязки (x-match all), fanout - всем;
+//   - при x-max-priority сначала выдается сообщение с большим приоритетом (выше максимума - как максимум),
+//     при равном приоритете и без x-max-priority - в порядке публикации;
+//   - отклоненное без requeue и истекшее сообщение уходит в x-dead-letter-exchange с заголовком x-death.
+type fakeBroker struct {
+	mu        sync.Mutex
+	exchanges map[string]string
+	queues    map[string]*fakeQueue
+	bindings  []fakeBinding
+}
+
+type fakeQueue struct {
+	args        amqp.Table
+	maxPriority uint8
+	messages    []amqp.Delivery
+}
+
+type fakeBinding struct {
+	exchange, key, queue string
+	args                 amqp.Table
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{
+		exchanges: map[string]string{"": "direct"},
+		queues:    make(map[string]*fakeQueue),
+	}
+}
+
+func (b *fakeBroker) Channel() (Channel, error)       { return &fakeChannel{broker: b}, nil }
+func (b *fakeBroker) Close() error                    { return nil }
+func (b *fakeBroker) NotifyClose() <-chan *amqp.Error { return make(chan *amqp.Error) }
+func (b *fakeBroker) IsClosed() bool                  { return false }
+
+// messages возвращает сообщения очереди в порядке хранения
+func (b *fakeBroker) messages(queue string) []amqp.Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if q, ok := b.queues[queue]; ok {
+		return append([]amqp.Delivery(nil), q.messages...)
+	}
+	return nil
+}
+
+// expire имитирует истечение TTL всех сообщений в очередях с префиксом prefix
+func (b *fakeBroker) expire(prefix string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for name, q := range b.queues {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		expired := q.messages
+		q.messages = nil
+		for _, msg := range expired {
+			b.deadLetter(name, msg, "expired")
+		}
+	}
+}
+
+func (b *fakeBroker) deadLetter(queue string, msg amqp.Delivery, reason string) {
+	q := b.queues[queue]
+	exchange, ok := q.args["x-dead-letter-exchange"].(string)
+	if !ok {
+		return
+	}
+	key := msg.RoutingKey
+	if k, ok := q.args["x-dead-letter-routing-key"].(string); ok {
+		key = k
+	}
+
+	msg.Headers = maps.Clone(msg.Headers)
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+	deaths, _ := msg.Headers["x-death"].([]interface{})
+	deaths = append([]interface{}(nil), deaths...)
+	counted := false
+	for i, d := range deaths {
+		death := maps.Clone(d.(amqp.Table))
+		if death["queue"] == queue && death["reason"] == reason {
+			death["count"] = death["count"].(int64) + 1
+			counted = true
+		}
+		deaths[i] = death
+	}
+	if !counted {
+		death := amqp.Table{"queue": queue, "reason": reason, "count": int64(1), "exchange": msg.Exchange}
+		deaths = append([]interface{}{death}, deaths...)
+	}
+	msg.Headers["x-death"] = deaths
+
+	b.route(exchange, key, msg)
+}
+
+// route раскладывает сообщение по очередям, привязанным к exchange; вызывается под b.mu
+func (b *fakeBroker) route(exchange, key string, msg amqp.Delivery) {
+	msg.Exchange = exchange
+	msg.RoutingKey = key
+
+	if exchange == "" {
+		if q, ok := b.queues[key]; ok {
+			q.messages = append(q.messages, msg)
+		}
+		return
+	}
+
+	kind := b.exchanges[exchange]
+	for _, binding := range b.bindings {
+		if binding.exchange != exchange || !bindingMatches(kind, binding, key, msg.Headers) {
+			continue
+		}
+		if q, ok := b.queues[binding.queue]; ok {
+			q.messages = append(q.messages, msg)
+		}
+	}
+}
+
+func bindingMatches(kind string, binding fakeBinding, key string, headers amqp.Table) bool {
+	switch kind {
+	case "fanout":
+		return true
+	case "topic":
+		return topicMatches(binding.key, key)
+	case "headers":
+		for name, value := range binding.args {
+			if name != "x-match" && headers[name] != value {
+				return false
+			}
+		}
+		return true
+	default:
+		return binding.key == key
+	}
+}
+
+type fakeChannel struct {
+	broker *fakeBroker
+}
+
+func (c *fakeChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if existing, ok := b.exchanges[name]; ok && existing != kind {
+		return &amqp.Error{Code: amqp.PreconditionFailed, Reason: "inequivalent arg 'type' for exchange '" + name + "'"}
+	}
+	b.exchanges[name] = kind
+	return nil
+}
+
+func (c *fakeChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (Queue, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		q = &fakeQueue{args: args}
+		if v, ok := args["x-max-priority"].(int32); ok {
+			q.maxPriority = uint8(v)
+		}
+		b.queues[name] = q
+	}
+	if !maps.Equal(q.args, args) {
+		return Queue{}, &amqp.Error{Code: amqp.PreconditionFailed, Reason: "inequivalent args for queue '" + name + "'"}
+	}
+	return Queue{Name: name, Messages: len(q.messages)}, nil
+}
+
+func (c *fakeChannel) QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (Queue, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		return Queue{}, &amqp.Error{Code: amqp.NotFound, Reason: "no queue '" + name + "'"}
+	}
+	return Queue{Name: name, Messages: len(q.messages)}, nil
+}
+
+func (c *fakeChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bindings = append(b.bindings, fakeBinding{exchange: exchange, key: key, queue: name, args: args})
+	return nil
+}
+
+func (c *fakeChannel) QueueUnbind(name, key, exchange string, args amqp.Table) error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := b.bindings[:0]
+	for _, binding := range b.bindings {
+		if binding.exchange != exchange || binding.key != key || binding.queue != name {
+			kept = append(kept, binding)
+		}
+	}
+	b.bindings = kept
+	return nil
+}
+
+func (c *fakeChannel) QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := 0
+	if q, ok := b.queues[name]; ok {
+		n = len(q.messages)
+	}
+	delete(b.queues, name)
+	return n, nil
+}
+
+func (c *fakeChannel) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queue]
+	if !ok {
+		return amqp.Delivery{}, false, &amqp.Error{Code: amqp.NotFound, Reason: "no queue '" + queue + "'"}
+	}
+	if len(q.messages) == 0 {
+		return amqp.Delivery{}, false, nil
+	}
+
+	next := 0
+	for i, msg := range q.messages {
+		if q.effectivePriority(msg) > q.effectivePriority(q.messages[next]) {
+			next = i
+		}
+	}
+	msg := q.messages[next]
+	q.messages = append(q.messages[:next], q.messages[next+1:]...)
+
+	if !autoAck {
+		msg.Acknowledger = &fakeAcknowledger{broker: b, queue: queue, msg: msg}
+	}
+	return msg, true, nil
+}
+
+func (q *fakeQueue) effectivePriority(msg amqp.Delivery) uint8 {
+	return min(msg.Priority, q.maxPriority)
+}
+
+func (c *fakeChannel) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.exchanges[exchange]; !ok {
+		return &amqp.Error{Code: amqp.NotFound, Reason: "no exchange '" + exchange + "'"}
+	}
+	b.route(exchange, key, amqp.Delivery{
+		Headers:      maps.Clone(msg.Headers),
+		ContentType:  msg.ContentType,
+		DeliveryMode: msg.DeliveryMode,
+		Priority:     msg.Priority,
+		Timestamp:    msg.Timestamp,
+		Body:         msg.Body,
+	})
+	return nil
+}
+
+func (c *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	return nil, fmt.Errorf("consume is not supported by the fake broker")
+}
+
+func (c *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error { return nil }
+func (c *fakeChannel) Close() error                                           { return nil }
+func (c *fakeChannel) NotifyClose() <-chan *amqp.Error                        { return make(chan *amqp.Error) }
+
+// fakeAcknowledger подтверждает сообщение, полученное через Get без autoAck
+type fakeAcknowledger struct {
+	broker *fakeBroker
+	queue  string
+	msg    amqp.Delivery
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error { return nil }
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	return a.Reject(tag, requeue)
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	b := a.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg := a.msg
+	msg.Acknowledger = nil
+	if requeue {
+		if q, ok := b.queues[a.queue]; ok {
+			msg.Redelivered = true
+			q.messages = append([]amqp.Delivery{msg}, q.messages...)
+		}
+		return nil
+	}
+	b.deadLetter(a.queue, msg, "rejected")
+	return nil
+}
+
+// topicMatches сопоставляет ключ маршрутизации с шаблоном привязки topic-exchange (* - одно слово, # - любое число слов)
+func topicMatches(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if matchWords(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && matchWords(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && key[0] == pattern[0] && matchWords(pattern[1:], key[1:])
+	}
+}
+
+// nopLogger отбрасывает записи логов
+type nopLogger struct{}
+
+func (nopLogger) Info(context.Context, string, string, map[string]interface{})         {}
+func (nopLogger) Debug(context.Context, string, string, map[string]interface{})        {}
+func (nopLogger) Error(context.Context, string, string, map[string]interface{}, error) {}
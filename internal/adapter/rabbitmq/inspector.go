@@ -0,0 +1,36 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+type queueInspector struct {
+	conn Connection
+}
+
+func NewQueueInspector(conn Connection) interfaces.QueueInspector {
+	return &queueInspector{conn: conn}
+}
+
+// QueueDepth возвращает число сообщений, ожидающих в очереди.
+// Пассивное объявление не создает очередь: если ее нет, брокер вернет ошибку.
+func (i *queueInspector) QueueDepth(ctx context.Context, queue string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	ch, err := i.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect queue %s: %w", queue, err)
+	}
+	return q.Messages, nil
+}
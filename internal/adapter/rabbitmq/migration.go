@@ -0,0 +1,166 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// MigrateKitchenQueues приводит очереди кухни к конфигурации и возвращает число перенесенных сообщений:
+//   - очереди типов заказов с устаревшими аргументами (x-max-priority) пересоздаются с сохранением сообщений;
+//   - заказы из общей kitchen_queue, оставшейся до разделения по типам, переотправляются в очереди своих типов,
+//     после чего kitchen_queue удаляется.
+//
+// Запускается при остановленных kitchen-воркерах. Пока привязки переключаются, заказ может оказаться
+// в двух очередях - кухня пропустит дубль по статусу.
+func MigrateKitchenQueues(ctx context.Context, conn Connection, maxPriority int) (int, error) {
+	topology, err := declareOrdersTopology(conn, maxPriority)
+	if err != nil {
+		return 0, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	moved := 0
+	for _, queue := range topology.mismatched {
+		n, err := rebuildKitchenQueue(ctx, ch, queue, maxPriority)
+		moved += n
+		if err != nil {
+			return moved, err
+		}
+	}
+
+	if topology.legacyQueue {
+		if err := ensureNoConsumers(ch, legacyKitchenQueue); err != nil {
+			return moved, err
+		}
+
+		// Ключ маршрутизации kitchen.<type>.<priority> сохранен в сообщении
+		n, err := moveMessages(ctx, ch, legacyKitchenQueue, func(msg amqp.Delivery) (string, string) {
+			return ordersExchange, msg.RoutingKey
+		})
+		moved += n
+		if err != nil {
+			return moved, err
+		}
+		if _, err := ch.QueueDelete(legacyKitchenQueue, false, true, false); err != nil {
+			return moved, fmt.Errorf("failed to delete %s: %w", legacyKitchenQueue, err)
+		}
+	}
+
+	return moved, nil
+}
+
+// rebuildKitchenQueue пересоздает очередь типа заказа с новыми аргументами. На время пересоздания
+// новые заказы и ожидающие сообщения хранятся во временной очереди <queue>_migration.
+func rebuildKitchenQueue(ctx context.Context, ch Channel, queue string, maxPriority int) (int, error) {
+	if err := ensureNoConsumers(ch, queue); err != nil {
+		return 0, err
+	}
+
+	orderType, ok := orderTypeOfQueue(queue)
+	if !ok {
+		return 0, fmt.Errorf("unknown kitchen queue %s", queue)
+	}
+	bindingKey := kitchenBindingKey(orderType)
+	holding := queue + "_migration"
+
+	toQueue := func(name string) func(amqp.Delivery) (string, string) {
+		return func(amqp.Delivery) (string, string) { return "", name }
+	}
+
+	// 1. Новые заказы идут во временную очередь, старая очередь отвязывается
+	if _, err := ch.QueueDeclare(holding, true, false, false, false, nil); err != nil {
+		return 0, fmt.Errorf("failed to declare %s: %w", holding, err)
+	}
+	if err := ch.QueueBind(holding, bindingKey, ordersExchange, false, nil); err != nil {
+		return 0, fmt.Errorf("failed to bind %s: %w", holding, err)
+	}
+	if err := ch.QueueUnbind(queue, bindingKey, ordersExchange, nil); err != nil {
+		return 0, fmt.Errorf("failed to unbind %s: %w", queue, err)
+	}
+
+	// 2. Ожидающие заказы переносятся во временную очередь, старая очередь удаляется
+	moved, err := moveMessages(ctx, ch, queue, toQueue(holding))
+	if err != nil {
+		return moved, err
+	}
+	if _, err := ch.QueueDelete(queue, false, true, false); err != nil {
+		return moved, fmt.Errorf("failed to delete %s: %w", queue, err)
+	}
+
+	// 3. Очередь объявляется заново с новыми аргументами и забирает заказы обратно
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, kitchenQueueArgs(maxPriority)); err != nil {
+		return moved, fmt.Errorf("failed to declare %s: %w", queue, err)
+	}
+	if err := ch.QueueBind(queue, bindingKey, ordersExchange, false, nil); err != nil {
+		return moved, fmt.Errorf("failed to bind %s: %w", queue, err)
+	}
+	if err := ch.QueueUnbind(holding, bindingKey, ordersExchange, nil); err != nil {
+		return moved, fmt.Errorf("failed to unbind %s: %w", holding, err)
+	}
+	if _, err := moveMessages(ctx, ch, holding, toQueue(queue)); err != nil {
+		return moved, err
+	}
+	if _, err := ch.QueueDelete(holding, false, true, false); err != nil {
+		return moved, fmt.Errorf("failed to delete %s: %w", holding, err)
+	}
+
+	return moved, nil
+}
+
+func ensureNoConsumers(ch Channel, queue string) error {
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", queue, err)
+	}
+	if q.Consumers > 0 {
+		return fmt.Errorf("%s has %d consumers, stop kitchen workers before migrating", queue, q.Consumers)
+	}
+	return nil
+}
+
+func orderTypeOfQueue(queue string) (orderType domain.OrderType, ok bool) {
+	for _, t := range domain.OrderTypes() {
+		if KitchenQueue(t) == queue {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// moveMessages перекладывает сообщения с сохранением свойств (приоритет, заголовки, время) по адресу из route.
+// Исходное сообщение подтверждается после публикации, поэтому при сбое возможен дубль, но не потеря.
+func moveMessages(ctx context.Context, ch Channel, from string, route func(amqp.Delivery) (exchange, key string)) (int, error) {
+	moved := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return moved, err
+		}
+
+		msg, ok, err := ch.Get(from, false)
+		if err != nil {
+			return moved, fmt.Errorf("failed to get message from %s: %w", from, err)
+		}
+		if !ok {
+			return moved, nil
+		}
+
+		exchange, key := route(msg)
+		err = ch.Publish(exchange, key, false, false, republishing(msg))
+		if err != nil {
+			msg.Nack(false, true)
+			return moved, fmt.Errorf("failed to move message from %s: %w", from, err)
+		}
+		if err := msg.Ack(false); err != nil {
+			return moved, fmt.Errorf("failed to ack moved message: %w", err)
+		}
+		moved++
+	}
+}
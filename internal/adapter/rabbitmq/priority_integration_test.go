@@ -0,0 +1,69 @@
+//go:build integration
+
+package rabbitmq
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Запуск: go test -tags integration ./internal/adapter/rabbitmq/ (нужен RabbitMQ из docker-compose на localhost:5672)
+func TestKitchenQueuePriorityBroker(t *testing.T) {
+	conn, err := Connect(config.RabbitMQConfig{Host: "localhost", Port: 5672, User: "guest", Password: "guest"})
+	if err != nil {
+		t.Skipf("RabbitMQ is not available: %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		t.Fatalf("open channel: %v", err)
+	}
+	defer ch.Close()
+
+	queue := fmt.Sprintf("kitchen_priority_test_%d", time.Now().UnixNano())
+	if _, err := ch.QueueDeclare(queue, false, true, false, false, kitchenQueueArgs(10)); err != nil {
+		t.Fatalf("declare queue: %v", err)
+	}
+	defer ch.QueueDelete(queue, false, false, false)
+
+	publish := func(body string, priority uint8) {
+		t.Helper()
+		err := ch.Publish("", queue, false, false, amqp.Publishing{Body: []byte(body), Priority: priority})
+		if err != nil {
+			t.Fatalf("publish %s: %v", body, err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		publish(fmt.Sprintf("low-%d", i), 1)
+	}
+	publish("high", 10)
+
+	// Публикации асинхронны: ждем, пока все сообщения окажутся в очереди
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		q, err := ch.QueueDeclarePassive(queue, false, true, false, false, nil)
+		if err != nil {
+			t.Fatalf("inspect queue: %v", err)
+		}
+		if q.Messages == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queue has %d messages, want 4", q.Messages)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	msg, ok, err := ch.Get(queue, true)
+	if err != nil || !ok {
+		t.Fatalf("get: ok=%v err=%v", ok, err)
+	}
+	if string(msg.Body) != "high" {
+		t.Errorf("first delivered message = %s, want high", msg.Body)
+	}
+}
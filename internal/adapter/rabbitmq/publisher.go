@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -19,7 +22,9 @@ func NewPublisher(conn Connection) interfaces.MessagePublisher {
 }
 
 func (p *publisher) PublishOrder(ctx context.Context, msg interfaces.OrderMessage) error {
-	return p.publishWithRetry(ctx, func(ch Channel) error {
+	routingKey := fmt.Sprintf("kitchen.%s.%d", msg.OrderType, msg.Priority)
+
+	return p.publishWithRetry(ctx, "orders_topic", routingKey, func(ctx context.Context, ch Channel) error {
 		// Declare exchange
 		if err := ch.ExchangeDeclare("orders_topic", "topic", true, false, false, false, nil); err != nil {
 			return fmt.Errorf("failed to declare exchange: %w", err)
@@ -30,13 +35,13 @@ func (p *publisher) PublishOrder(ctx context.Context, msg interfaces.OrderMessag
 			return fmt.Errorf("failed to marshal message: %w", err)
 		}
 
-		routingKey := fmt.Sprintf("kitchen.%s.%d", msg.OrderType, msg.Priority)
-
 		err = ch.Publish("orders_topic", routingKey, false, false, amqp.Publishing{
 			DeliveryMode: amqp.Persistent,
 			ContentType:  "application/json",
 			Body:         body,
 			Priority:     uint8(msg.Priority),
+			Timestamp:    time.Now(),
+			Headers:      messageHeaders(ctx),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to publish message: %w", err)
@@ -47,7 +52,7 @@ func (p *publisher) PublishOrder(ctx context.Context, msg interfaces.OrderMessag
 }
 
 func (p *publisher) PublishStatusUpdate(ctx context.Context, msg interfaces.StatusUpdateMessage) error {
-	return p.publishWithRetry(ctx, func(ch Channel) error {
+	return p.publishWithRetry(ctx, "notifications_fanout", "", func(ctx context.Context, ch Channel) error {
 		// Declare exchange
 		if err := ch.ExchangeDeclare("notifications_fanout", "fanout", true, false, false, false, nil); err != nil {
 			return fmt.Errorf("failed to declare exchange: %w", err)
@@ -61,6 +66,8 @@ func (p *publisher) PublishStatusUpdate(ctx context.Context, msg interfaces.Stat
 		err = ch.Publish("notifications_fanout", "", false, false, amqp.Publishing{
 			ContentType: "application/json",
 			Body:        body,
+			Timestamp:   time.Now(),
+			Headers:     messageHeaders(ctx),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to publish message: %w", err)
@@ -70,14 +77,46 @@ func (p *publisher) PublishStatusUpdate(ctx context.Context, msg interfaces.Stat
 	})
 }
 
-// publishWithRetry выполняет публикацию с повторными попытками
-func (p *publisher) publishWithRetry(ctx context.Context, publishFn func(Channel) error) error {
+// messageHeaders переносит идентификатор запроса и контекст трейса в заголовки сообщения
+func messageHeaders(ctx context.Context) amqp.Table {
+	headers := amqp.Table{}
+	if requestID := logger.RequestID(ctx); requestID != "" {
+		headers[logger.RequestIDHeader] = requestID
+	}
+	if sc, ok := tracing.SpanContextFromContext(ctx); ok {
+		headers[tracing.TraceparentHeader] = sc.Traceparent()
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// publishWithRetry выполняет публикацию с повторными попытками.
+// Все попытки входят в один спан, чтобы было видно время, ушедшее на повторы.
+func (p *publisher) publishWithRetry(ctx context.Context, exchange, routingKey string, publishFn func(context.Context, Channel) error) (err error) {
 	const maxRetries = 3
 	const retryDelay = 2 * time.Second
 
+	ctx, span := tracing.Start(ctx, "rabbitmq.publish "+exchange, tracing.KindProducer)
+	span.SetAttr("messaging.destination", exchange)
+	span.SetAttr("messaging.routing_key", routingKey)
+	defer func() {
+		if err != nil {
+			metrics.PublishFailures.Inc(exchange)
+		}
+		span.SetError(err)
+		span.End()
+	}()
+
 	var lastErr error
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		span.SetAttr("messaging.attempts", attempt+1)
+		if attempt > 0 {
+			metrics.PublishRetries.Inc(exchange)
+		}
+
 		// Проверяем контекст перед попыткой
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -99,7 +138,7 @@ func (p *publisher) publishWithRetry(ctx context.Context, publishFn func(Channel
 		}
 
 		// Пытаемся опубликовать
-		err = publishFn(ch)
+		err = publishFn(ctx, ch)
 		ch.Close()
 
 		if err == nil {
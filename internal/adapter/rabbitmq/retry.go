@@ -0,0 +1,145 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// ordersRetryExchange раскладывает заказы по очередям задержки по заголовку retryDelayHeader
+	ordersRetryExchange = "orders_retry"
+	retryDelayHeader    = "x-retry-delay-ms"
+	retryQueuePrefix    = "kitchen_retry_"
+)
+
+// retryPolicy - сколько раз и с какими задержками повторять обработку заказа
+type retryPolicy struct {
+	maxAttempts int
+	// delays[i] - задержка после неудачной попытки i+1
+	delays []time.Duration
+}
+
+func newRetryPolicy(cfg config.RabbitMQConfig) retryPolicy {
+	base := time.Duration(cfg.RetryBaseDelayMs) * time.Millisecond
+	limit := time.Duration(cfg.RetryMaxDelayMs) * time.Millisecond
+
+	policy := retryPolicy{maxAttempts: max(cfg.MaxAttempts, 1)}
+	delay := base
+	for attempt := 1; attempt < policy.maxAttempts; attempt++ {
+		policy.delays = append(policy.delays, min(delay, limit))
+		if delay < limit {
+			delay *= 2
+		}
+	}
+	return policy
+}
+
+// delay возвращает задержку перед повтором после неудачной попытки attempt; false - попытки исчерпаны
+func (p retryPolicy) delay(attempt int) (time.Duration, bool) {
+	if attempt < 1 || attempt > len(p.delays) {
+		return 0, false
+	}
+	return p.delays[attempt-1], true
+}
+
+// retryQueue - очередь задержки: сообщение лежит в ней TTL и возвращается в orders_topic
+// со своим исходным ключом kitchen.<type>.<priority>
+func retryQueue(delay time.Duration) string {
+	return fmt.Sprintf("%s%dms", retryQueuePrefix, delay.Milliseconds())
+}
+
+// declareRetryTopology объявляет exchange повторов и по очереди задержки на каждую ступень политики
+func declareRetryTopology(conn Connection, policy retryPolicy) error {
+	return withChannel(conn, func(ch Channel) error {
+		if err := ch.ExchangeDeclare(ordersRetryExchange, "headers", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare retry exchange: %w", err)
+		}
+
+		for _, delay := range policy.delays {
+			queue := retryQueue(delay)
+			args := amqp.Table{
+				"x-message-ttl":          delay.Milliseconds(),
+				"x-dead-letter-exchange": ordersExchange,
+			}
+			if _, err := ch.QueueDeclare(queue, true, false, false, false, args); err != nil {
+				return fmt.Errorf("failed to declare retry queue %s: %w", queue, err)
+			}
+
+			binding := amqp.Table{
+				"x-match":        "all",
+				retryDelayHeader: strconv.FormatInt(delay.Milliseconds(), 10),
+			}
+			if err := ch.QueueBind(queue, "", ordersRetryExchange, false, binding); err != nil {
+				return fmt.Errorf("failed to bind retry queue %s: %w", queue, err)
+			}
+		}
+		return nil
+	})
+}
+
+// retryCount считает по заголовку x-death, сколько раз сообщение уже отлежало в очередях задержки
+func retryCount(headers amqp.Table) int {
+	deaths, _ := headers["x-death"].([]interface{})
+
+	count := 0
+	for _, d := range deaths {
+		death, ok := d.(amqp.Table)
+		if !ok {
+			continue
+		}
+		queue, _ := death["queue"].(string)
+		reason, _ := death["reason"].(string)
+		if !strings.HasPrefix(queue, retryQueuePrefix) || reason != "expired" {
+			continue
+		}
+		if n, ok := death["count"].(int64); ok {
+			count += int(n)
+		}
+	}
+	return count
+}
+
+// isPermanent - ошибка, которую повтор не исправит: сообщение сразу уходит в DLQ
+func isPermanent(err error) bool {
+	return errors.Is(err, domain.ErrMalformedOrderMessage) || errors.Is(err, domain.ErrOrderNotFound)
+}
+
+// scheduleRetry публикует копию сообщения в очередь задержки; исходное сообщение подтверждает вызывающий
+func scheduleRetry(ch Channel, msg amqp.Delivery, delay time.Duration) error {
+	publishing := republishing(msg)
+	publishing.Headers = maps.Clone(msg.Headers)
+	if publishing.Headers == nil {
+		publishing.Headers = amqp.Table{}
+	}
+	publishing.Headers[retryDelayHeader] = strconv.FormatInt(delay.Milliseconds(), 10)
+
+	return ch.Publish(ordersRetryExchange, msg.RoutingKey, false, false, publishing)
+}
+
+// republishing копирует свойства полученного сообщения (приоритет, заголовки, время) для повторной публикации
+func republishing(msg amqp.Delivery) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:         msg.Headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    msg.DeliveryMode,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		Expiration:      msg.Expiration,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		UserId:          msg.UserId,
+		AppId:           msg.AppId,
+		Body:            msg.Body,
+	}
+}
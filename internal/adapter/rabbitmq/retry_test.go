@@ -0,0 +1,79 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+func TestFailedOrderEndsInDLQ(t *testing.T) {
+	cfg := config.RabbitMQConfig{MaxPriority: 10, MaxAttempts: 3, RetryBaseDelayMs: 100, RetryMaxDelayMs: 1000}
+
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{"transient error after max attempts", errors.New("database is unavailable"), cfg.MaxAttempts},
+		{"permanent error at once", domain.ErrMalformedOrderMessage, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			broker := newFakeBroker()
+			c := NewConsumer(broker, cfg, nopLogger{}, 1, 1, nil).(*consumer)
+			if _, err := declareOrdersTopology(broker, cfg.MaxPriority); err != nil {
+				t.Fatalf("declare topology: %v", err)
+			}
+			if err := declareRetryTopology(broker, c.retry); err != nil {
+				t.Fatalf("declare retry topology: %v", err)
+			}
+
+			order := interfaces.OrderMessage{OrderNumber: "ORD_1", OrderType: domain.OrderTypeDelivery, Priority: domain.PriorityMedium}
+			if err := NewPublisher(broker).PublishOrder(ctx, order); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+
+			queue := KitchenQueue(order.OrderType)
+			ch, _ := broker.Channel()
+			attempts := 0
+			for {
+				msg, ok, err := ch.Get(queue, false)
+				if err != nil {
+					t.Fatalf("get: %v", err)
+				}
+				if !ok {
+					break
+				}
+				attempts++
+				if attempts > cfg.MaxAttempts {
+					t.Fatalf("order is still retried after %d attempts", cfg.MaxAttempts)
+				}
+				c.settleOrder(ctx, ch, queue, msg, tt.err)
+				// Заказ отлежал задержку и вернулся в свою очередь
+				broker.expire(retryQueuePrefix)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			dead := broker.messages(kitchenDLQ)
+			if len(dead) != 1 {
+				t.Fatalf("%s has %d messages, want 1", kitchenDLQ, len(dead))
+			}
+			var got interfaces.OrderMessage
+			if err := json.Unmarshal(dead[0].Body, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if got.OrderNumber != order.OrderNumber {
+				t.Errorf("dead-lettered order = %s, want %s", got.OrderNumber, order.OrderNumber)
+			}
+		})
+	}
+}
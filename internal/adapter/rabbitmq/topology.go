@@ -0,0 +1,166 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	ordersExchange    = "orders_topic"
+	ordersDLXExchange = "orders_dlq"
+	kitchenDLQ        = "kitchen_queue_dlq"
+	// kitchenDLQKey - ключ, с которым очереди кухни отправляют сообщения в orders_dlq. Exchange прямой,
+	// а заказы публикуются с ключом kitchen.<type>.<priority>, поэтому исходный ключ до DLQ не дойдет.
+	kitchenDLQKey = "kitchen"
+
+	// legacyKitchenQueue - общая очередь кухни до разделения по типам заказов (привязка kitchen.#)
+	legacyKitchenQueue      = "kitchen_queue"
+	legacyKitchenBindingKey = "kitchen.#"
+)
+
+// KitchenQueue возвращает очередь кухни для типа заказа. Заказы публикуются с ключом
+// kitchen.<type>.<priority> и попадают в очередь своего типа.
+func KitchenQueue(orderType domain.OrderType) string {
+	return "kitchen_" + string(orderType) + "_queue"
+}
+
+// KitchenQueues возвращает очереди всех типов заказов
+func KitchenQueues() []string {
+	types := domain.OrderTypes()
+	queues := make([]string, len(types))
+	for i, t := range types {
+		queues[i] = KitchenQueue(t)
+	}
+	return queues
+}
+
+func kitchenBindingKey(orderType domain.OrderType) string {
+	return "kitchen." + string(orderType) + ".*"
+}
+
+// kitchenQueueArgs - аргументы очередей кухни: DLX с ключом kitchenDLQKey и, если включены приоритеты, x-max-priority
+func kitchenQueueArgs(maxPriority int) amqp.Table {
+	args := amqp.Table{
+		"x-dead-letter-exchange":    ordersDLXExchange,
+		"x-dead-letter-routing-key": kitchenDLQKey,
+	}
+	if maxPriority > 0 {
+		args["x-max-priority"] = int32(maxPriority)
+	}
+	return args
+}
+
+// isPreconditionFailed - брокер отказался переобъявить очередь с другими аргументами
+func isPreconditionFailed(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed
+}
+
+func isNotFound(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound
+}
+
+// withChannel выполняет fn на отдельном канале: ошибка объявления закрывает канал на стороне брокера
+func withChannel(conn Connection, fn func(Channel) error) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+	return fn(ch)
+}
+
+// ordersTopology - состояние очередей кухни после объявления
+type ordersTopology struct {
+	// mismatched - очереди, созданные раньше с другими аргументами (x-max-priority, ключ DLQ); используются как есть до миграции
+	mismatched []string
+	// legacyQueue - осталась общая kitchen_queue; она отвязана от exchange и дочитывается general-воркерами
+	legacyQueue bool
+}
+
+// DeclareOrdersTopology объявляет exchange заказов, DLQ и очереди кухни по типам заказов,
+// чтобы заказы не терялись как немаршрутизируемые до запуска первого воркера
+func DeclareOrdersTopology(conn Connection, cfg config.RabbitMQConfig) error {
+	_, err := declareOrdersTopology(conn, cfg.MaxPriority)
+	return err
+}
+
+// declareOrdersTopology объявляет топологию заказов. Аргументы существующей очереди изменить нельзя:
+// брокер закроет канал с 406 PRECONDITION_FAILED, тогда очередь используется как есть до запуска миграции.
+func declareOrdersTopology(conn Connection, maxPriority int) (ordersTopology, error) {
+	var topology ordersTopology
+
+	err := withChannel(conn, func(ch Channel) error {
+		// Declare main exchange
+		if err := ch.ExchangeDeclare(ordersExchange, "topic", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare orders exchange: %w", err)
+		}
+
+		// Declare DLQ exchange
+		if err := ch.ExchangeDeclare(ordersDLXExchange, "direct", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare DLQ exchange: %w", err)
+		}
+
+		// Declare DLQ queue
+		if _, err := ch.QueueDeclare(kitchenDLQ, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare DLQ: %w", err)
+		}
+
+		// Bind DLQ
+		if err := ch.QueueBind(kitchenDLQ, kitchenDLQKey, ordersDLXExchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind DLQ: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return topology, err
+	}
+
+	// Declare a queue per order type with DLQ binding and priority levels
+	for _, orderType := range domain.OrderTypes() {
+		queue := KitchenQueue(orderType)
+
+		err := withChannel(conn, func(ch Channel) error {
+			_, err := ch.QueueDeclare(queue, true, false, false, false, kitchenQueueArgs(maxPriority))
+			return err
+		})
+		if isPreconditionFailed(err) {
+			err = withChannel(conn, func(ch Channel) error {
+				_, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+				return err
+			})
+			topology.mismatched = append(topology.mismatched, queue)
+		}
+		if err != nil {
+			return topology, fmt.Errorf("failed to declare kitchen queue %s: %w", queue, err)
+		}
+
+		err = withChannel(conn, func(ch Channel) error {
+			return ch.QueueBind(queue, kitchenBindingKey(orderType), ordersExchange, false, nil)
+		})
+		if err != nil {
+			return topology, fmt.Errorf("failed to bind kitchen queue %s: %w", queue, err)
+		}
+	}
+
+	// Общая очередь больше не получает новых заказов, иначе каждый заказ попадал бы в две очереди
+	err = withChannel(conn, func(ch Channel) error {
+		if _, err := ch.QueueDeclarePassive(legacyKitchenQueue, true, false, false, false, nil); err != nil {
+			return err
+		}
+		return ch.QueueUnbind(legacyKitchenQueue, legacyKitchenBindingKey, ordersExchange, nil)
+	})
+	switch {
+	case err == nil:
+		topology.legacyQueue = true
+	case !isNotFound(err):
+		return topology, fmt.Errorf("failed to detach %s: %w", legacyKitchenQueue, err)
+	}
+
+	return topology, nil
+}
@@ -0,0 +1,66 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+func TestKitchenQueuePriority(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxPriority int
+		wantFirst   string
+	}{
+		{"high priority order jumps the queue", 10, "ORD_HIGH"},
+		{"without x-max-priority the queue is FIFO", 0, "ORD_LOW_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newFakeBroker()
+			if _, err := declareOrdersTopology(broker, tt.maxPriority); err != nil {
+				t.Fatalf("declare topology: %v", err)
+			}
+
+			publisher := NewPublisher(broker)
+			orders := []interfaces.OrderMessage{
+				{OrderNumber: "ORD_LOW_1", OrderType: domain.OrderTypeTakeout, Priority: domain.PriorityLow},
+				{OrderNumber: "ORD_LOW_2", OrderType: domain.OrderTypeTakeout, Priority: domain.PriorityLow},
+				{OrderNumber: "ORD_LOW_3", OrderType: domain.OrderTypeTakeout, Priority: domain.PriorityLow},
+				{OrderNumber: "ORD_HIGH", OrderType: domain.OrderTypeTakeout, Priority: domain.PriorityHigh},
+			}
+			for _, order := range orders {
+				if err := publisher.PublishOrder(context.Background(), order); err != nil {
+					t.Fatalf("publish %s: %v", order.OrderNumber, err)
+				}
+			}
+
+			ch, _ := broker.Channel()
+			msg, ok, err := ch.Get(KitchenQueue(domain.OrderTypeTakeout), true)
+			if err != nil || !ok {
+				t.Fatalf("get: ok=%v err=%v", ok, err)
+			}
+
+			var got interfaces.OrderMessage
+			if err := json.Unmarshal(msg.Body, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if got.OrderNumber != tt.wantFirst {
+				t.Errorf("first delivered order = %s, want %s", got.OrderNumber, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestKitchenQueueArgs(t *testing.T) {
+	if _, ok := kitchenQueueArgs(0)["x-max-priority"]; ok {
+		t.Error("x-max-priority is set with priorities disabled")
+	}
+	if got := kitchenQueueArgs(10)["x-max-priority"]; got != int32(10) {
+		t.Errorf("x-max-priority = %#v, want int32(10)", got)
+	}
+}
@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Exporter получает завершенные спаны. Export не должен блокировать обработку запросов.
+type Exporter interface {
+	Export(span SpanData)
+	Shutdown(ctx context.Context) error
+}
+
+// fileExporter дописывает спаны в файл в формате JSON lines
+type fileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewFileExporter(path string) (Exporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	return &fileExporter{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (e *fileExporter) Export(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enc.Encode(span)
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
+
+const (
+	httpExportBatch    = 100
+	httpExportInterval = time.Second
+	httpExportBuffer   = 2048
+)
+
+// httpExporter отправляет спаны пачками POST-запросом JSON-массива на коллектор.
+// При переполнении буфера спаны отбрасываются, чтобы медленный коллектор не тормозил сервис.
+type httpExporter struct {
+	endpoint string
+	client   *http.Client
+	spans    chan SpanData
+	done     chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewHTTPExporter(endpoint string) Exporter {
+	e := &httpExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		spans:    make(chan SpanData, httpExportBuffer),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *httpExporter) Export(span SpanData) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.spans <- span:
+	default:
+	}
+}
+
+func (e *httpExporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(httpExportInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, httpExportBatch)
+	flush := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case span, ok := <-e.spans:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= httpExportBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (e *httpExporter) send(batch []SpanData) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// Shutdown отправляет оставшиеся спаны; новые спаны после вызова не принимаются
+func (e *httpExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.spans)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+package admission
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+// BacklogMonitor периодически опрашивает суммарную глубину очередей кухни, чтобы не принимать заказы,
+// которые кухня не успеет приготовить. Если брокер недоступен, заказы принимаются:
+// они дождутся отправки в outbox.
+type BacklogMonitor struct {
+	inspector    interfaces.QueueInspector
+	logger       logger.Logger
+	queues       []string
+	maxBacklog   int
+	pollInterval time.Duration
+
+	depth     atomic.Int64
+	saturated atomic.Bool
+}
+
+// NewBacklogMonitor создает монитор очередей queues: лимит max_kitchen_backlog действует на их сумму
+func NewBacklogMonitor(inspector interfaces.QueueInspector, logger logger.Logger, cfg config.AdmissionConfig, queues []string) *BacklogMonitor {
+	return &BacklogMonitor{
+		inspector:    inspector,
+		logger:       logger,
+		queues:       queues,
+		maxBacklog:   cfg.MaxKitchenBacklog,
+		pollInterval: time.Duration(cfg.BacklogPollMs) * time.Millisecond,
+	}
+}
+
+// Run опрашивает очереди до отмены контекста
+func (m *BacklogMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		m.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *BacklogMonitor) poll(ctx context.Context) {
+	depth := 0
+	for _, queue := range m.queues {
+		n, err := m.inspector.QueueDepth(ctx, queue)
+		if err != nil {
+			if ctx.Err() == nil {
+				m.logger.Error(ctx, "backlog_check_failed", "Failed to read kitchen queue depth", map[string]interface{}{"queue": queue}, err)
+			}
+			m.saturated.Store(false)
+			return
+		}
+		depth += n
+	}
+
+	m.depth.Store(int64(depth))
+	saturated := depth >= m.maxBacklog
+	if saturated != m.saturated.Swap(saturated) {
+		m.logger.Info(ctx, "kitchen_backlog_changed", "Kitchen backlog state changed", map[string]interface{}{
+			"queues":    m.queues,
+			"depth":     depth,
+			"limit":     m.maxBacklog,
+			"saturated": saturated,
+		})
+	}
+}
+
+// Saturated сообщает, что очереди кухни переполнены
+func (m *BacklogMonitor) Saturated() bool {
+	return m.saturated.Load()
+}
+
+// Depth возвращает последнюю известную суммарную глубину очередей
+func (m *BacklogMonitor) Depth() int {
+	return int(m.depth.Load())
+}
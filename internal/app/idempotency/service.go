@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+const (
+	// Ключи старше ttl считаются истекшими и могут быть использованы заново
+	ttl = 24 * time.Hour
+	// lease - сколько ключ удерживается незавершенным запросом; после этого его можно занять снова
+	lease = time.Minute
+)
+
+type Service struct {
+	repo   interfaces.IdempotencyRepository
+	logger logger.Logger
+}
+
+func NewService(repo interfaces.IdempotencyRepository, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Begin занимает ключ клиента principal перед выполнением запроса: одинаковые ключи разных клиентов не пересекаются.
+// Возвращает сохраненную запись, если запрос уже был выполнен и ответ нужно повторить,
+// или nil и token аренды, если запрос нужно выполнить. Complete и Release принимают только этот token,
+// поэтому запрос, чью аренду перехватили, не затрет и не удалит чужой ключ.
+func (s *Service) Begin(ctx context.Context, principal, key, requestHash string) (*domain.IdempotencyRecord, string, error) {
+	token, err := newLeaseToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	record, err := s.repo.Reserve(ctx, principal, key, token, requestHash, lease)
+	if err != nil {
+		return nil, "", err
+	}
+	if record == nil {
+		return nil, token, nil
+	}
+
+	if record.IsExpired(ttl) {
+		if err := s.repo.DeleteExpired(ctx, principal, key, time.Now().Add(-ttl)); err != nil {
+			return nil, "", err
+		}
+		record, err = s.repo.Reserve(ctx, principal, key, token, requestHash, lease)
+		if err != nil {
+			return nil, "", err
+		}
+		if record == nil {
+			return nil, token, nil
+		}
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, "", domain.ErrIdempotencyKeyMismatch
+	}
+
+	if !record.Completed {
+		return nil, "", domain.ErrIdempotencyKeyInProgress
+	}
+
+	s.logger.Debug(ctx, "idempotent_replay", "Replaying stored response", map[string]interface{}{"idempotency_key": key})
+
+	return record, "", nil
+}
+
+// Complete сохраняет ответ для повторных запросов с тем же ключом
+func (s *Service) Complete(ctx context.Context, principal, key, token string, responseCode int, responseBody []byte) error {
+	return s.repo.Complete(ctx, principal, key, token, responseCode, responseBody)
+}
+
+// Release освобождает ключ, если запрос не удался, чтобы клиент мог повторить его
+func (s *Service) Release(ctx context.Context, principal, key, token string) error {
+	return s.repo.Release(ctx, principal, key, token)
+}
+
+func newLeaseToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency lease token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -2,11 +2,16 @@ package kitchen
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
 	"github.com/YelzhanWeb/pizzas/internal/domain"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 )
@@ -17,8 +22,26 @@ type Service struct {
 	publisher         interfaces.MessagePublisher
 	logger            logger.Logger
 	workerName        string
-	orderTypes        []string
+	orderTypes        []domain.OrderType
 	heartbeatInterval time.Duration
+	ovenSlots         int
+
+	// Время последнего успешного heartbeat (unix nano), 0 - воркер еще не зарегистрирован
+	lastHeartbeat atomic.Int64
+	stopHeartbeat context.CancelFunc
+
+	// Заказы, которые сейчас готовятся: номер -> отмена ожидания готовки.
+	// Причина отмены ErrWorkerDraining означает возврат заказа в очередь при остановке.
+	mu       sync.Mutex
+	inFlight map[string]context.CancelCauseFunc
+	// draining запрещает брать новые заказы, handingBack - дедлайн прошел и заказы возвращаются в очередь;
+	// active считает ProcessOrder в работе
+	draining    bool
+	handingBack bool
+	active      sync.WaitGroup
+
+	// loadMu сериализует запись загрузки в workers, чтобы последним записалось актуальное значение
+	loadMu sync.Mutex
 }
 
 func NewService(
@@ -27,22 +50,21 @@ func NewService(
 	publisher interfaces.MessagePublisher,
 	logger logger.Logger,
 	workerName string,
-	orderTypes string,
+	orderTypes []domain.OrderType,
 	heartbeatInterval int,
+	ovenSlots int,
 ) *Service {
-	var types []string
-	if orderTypes != "" {
-		types = strings.Split(orderTypes, ",")
-	}
-
 	return &Service{
 		orderRepo:         orderRepo,
 		workerRepo:        workerRepo,
 		publisher:         publisher,
 		logger:            logger,
 		workerName:        workerName,
-		orderTypes:        types,
+		orderTypes:        orderTypes,
 		heartbeatInterval: time.Duration(heartbeatInterval) * time.Second,
+		ovenSlots:         ovenSlots,
+		inFlight:          make(map[string]context.CancelCauseFunc),
+		stopHeartbeat:     func() {},
 	}
 }
 
@@ -56,6 +78,8 @@ func (s *Service) Start(ctx context.Context) error {
 		}
 		worker.Status = domain.WorkerStatusOnline
 		worker.LastSeen = time.Now()
+		worker.Capacity = s.ovenSlots
+		worker.CurrentLoad = 0
 		if err := s.workerRepo.Update(ctx, worker); err != nil {
 			return err
 		}
@@ -63,21 +87,31 @@ func (s *Service) Start(ctx context.Context) error {
 		// Создаем нового
 		typeStr := "general"
 		if len(s.orderTypes) > 0 {
-			typeStr = strings.Join(s.orderTypes, ",")
+			names := make([]string, len(s.orderTypes))
+			for i, t := range s.orderTypes {
+				names[i] = string(t)
+			}
+			typeStr = strings.Join(names, ",")
 		}
 		worker, err = domain.NewWorker(s.workerName, typeStr)
 		if err != nil {
 			return err
 		}
+		worker.Capacity = s.ovenSlots
 		if err := s.workerRepo.Create(ctx, worker); err != nil {
 			return err
 		}
 	}
 
-	s.logger.Info("worker_registered", fmt.Sprintf("Worker %s registered", s.workerName), "", nil)
+	s.logger.Info(ctx, "worker_registered", fmt.Sprintf("Worker %s registered", s.workerName), map[string]interface{}{
+		"oven_slots": s.ovenSlots,
+	})
+	s.lastHeartbeat.Store(time.Now().UnixNano())
 
-	// Запуск Heartbeat в фоне
-	go s.heartbeatLoop(ctx)
+	// Запуск Heartbeat в фоне; останавливается в Shutdown, чтобы не вернуть воркеру статус online
+	heartbeatCtx, stop := context.WithCancel(ctx)
+	s.stopHeartbeat = stop
+	go s.heartbeatLoop(heartbeatCtx)
 
 	return nil
 }
@@ -92,15 +126,75 @@ func (s *Service) heartbeatLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			if err := s.workerRepo.UpdateHeartbeat(ctx, s.workerName); err != nil {
-				s.logger.Error("heartbeat_failed", "Failed to update heartbeat", "", nil, err)
+				metrics.HeartbeatFailures.Inc(s.workerName)
+				s.logger.Error(ctx, "heartbeat_failed", "Failed to update heartbeat", nil, err)
 			} else {
-				s.logger.Debug("heartbeat_sent", "Heartbeat sent", "", nil)
+				s.lastHeartbeat.Store(time.Now().UnixNano())
+				s.logger.Debug(ctx, "heartbeat_sent", "Heartbeat sent", nil)
 			}
 		}
 	}
 }
 
+// CheckHeartbeat возвращает ошибку, если воркер не зарегистрирован или пропустил два heartbeat подряд
+func (s *Service) CheckHeartbeat(ctx context.Context) error {
+	last := s.lastHeartbeat.Load()
+	if last == 0 {
+		return errors.New("worker is not registered")
+	}
+
+	age := time.Since(time.Unix(0, last))
+	if age > 2*s.heartbeatInterval {
+		return fmt.Errorf("last successful heartbeat was %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+// handBackTimeout - сколько после дедлайна ждать, пока прерванные заказы вернутся в очередь
+const handBackTimeout = 5 * time.Second
+
+// Drain перестает принимать заказы и ждет завершения текущих до дедлайна ctx.
+// Не успевшие заказы возвращаются в статус received, а их сообщения - в очередь.
+func (s *Service) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	inFlight := len(s.inFlight)
+	s.mu.Unlock()
+
+	s.logger.Info(ctx, "drain_started", "Waiting for orders in progress", map[string]interface{}{
+		"in_flight": inFlight,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		s.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	s.handingBack = true
+	for _, abort := range s.inFlight {
+		abort(domain.ErrWorkerDraining)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(handBackTimeout):
+		return fmt.Errorf("orders were not handed back within %s", handBackTimeout)
+	}
+}
+
 func (s *Service) Shutdown(ctx context.Context) error {
+	s.stopHeartbeat()
+
 	worker, err := s.workerRepo.FindByName(ctx, s.workerName)
 	if err != nil {
 		return err
@@ -109,71 +203,265 @@ func (s *Service) Shutdown(ctx context.Context) error {
 	return s.workerRepo.Update(ctx, worker)
 }
 
-func (s *Service) ProcessOrder(ctx context.Context, msg interfaces.OrderMessage) error {
-	// 1. Проверка специализации
+func (s *Service) ProcessOrder(ctx context.Context, msg interfaces.OrderMessage) (err error) {
+	ctx, span := tracing.Start(ctx, "kitchen.process_order", tracing.KindInternal)
+	span.SetAttr("order_number", msg.OrderNumber)
+	span.SetAttr("order_type", string(msg.OrderType))
+	span.SetAttr("worker_name", s.workerName)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	// Остановленный воркер не берет новые заказы: сообщение вернется в очередь
+	if !s.beginOrder() {
+		return domain.ErrWorkerDraining
+	}
+	defer s.active.Done()
+
+	// 1. Проверка специализации: воркер слушает только очереди своих типов,
+	// чужой заказ может прийти лишь из общей kitchen_queue, оставшейся до их разделения
 	if len(s.orderTypes) > 0 {
 		supported := false
 		for _, t := range s.orderTypes {
-			if t == string(msg.OrderType) {
+			if t == msg.OrderType {
 				supported = true
 				break
 			}
 		}
 		if !supported {
-			// Возвращаем ошибку, начинающуюся с "worker", чтобы consumer.go сделал Nack с requeue
-			return fmt.Errorf("worker %s cannot handle order type %s", s.workerName, msg.OrderType)
+			// consumer вернет сообщение в очередь для других воркеров
+			return fmt.Errorf("worker %s, order type %s: %w", s.workerName, msg.OrderType, domain.ErrUnsupportedOrderType)
 		}
 	}
 
-	s.logger.Debug("order_processing_started", fmt.Sprintf("Processing order %s", msg.OrderNumber), "", map[string]interface{}{"order": msg.OrderNumber})
+	// Повар видит позиции вместе с выбранными модификаторами
+	items := make([]string, len(msg.Items))
+	for i, item := range msg.Items {
+		items[i] = item.Description()
+	}
+
+	s.logger.Debug(ctx, "order_processing_started", fmt.Sprintf("Processing order %s", msg.OrderNumber), map[string]interface{}{
+		"order": msg.OrderNumber,
+		"items": items,
+	})
 
 	// Находим заказ в БД
-	order, err := s.orderRepo.FindByNumber(ctx, msg.OrderNumber)
+	loadCtx, loadSpan := tracing.Start(ctx, "kitchen.load_order", tracing.KindInternal)
+	order, err := s.orderRepo.FindByNumber(loadCtx, msg.OrderNumber)
+	loadSpan.SetError(err)
+	loadSpan.End()
 	if err != nil {
 		return err
 	}
 
-	// Идемпотентность: если уже готовим или готово, пропускаем
-	if order.Status != domain.StatusReceived {
+	// Идемпотентность: если уже готовим или готово, пропускаем. Исключение - заказ в cooking, взятый этим воркером,
+	// но уже не готовящийся (сбой записи статуса после начала готовки): повтор сообщения возобновляет готовку
+	resume := order.Status == domain.StatusCooking && order.ProcessedBy != nil && *order.ProcessedBy == s.workerName
+	if order.Status != domain.StatusReceived && !resume {
+		span.SetAttr("skipped", string(order.Status))
+		return nil
+	}
+
+	// Регистрируем заказ до начала готовки, чтобы не пропустить отмену
+	cookCtx, abort := context.WithCancelCause(ctx)
+	if !s.trackOrder(order.Number, abort) {
+		abort(nil)
+		// Дубль сообщения: заказ уже готовится этим воркером по другой доставке
+		span.SetAttr("skipped", "duplicate")
+		s.logger.Debug(ctx, "order_skipped", fmt.Sprintf("Order %s is already being cooked", msg.OrderNumber), nil)
 		return nil
 	}
+	s.reportLoad(ctx)
+	defer func() {
+		s.untrackOrder(order.Number)
+		s.reportLoad(ctx)
+	}()
+	if errors.Is(context.Cause(cookCtx), domain.ErrWorkerDraining) {
+		return domain.ErrWorkerDraining
+	}
 
 	// 2. Начало готовки (Status: Cooking)
-	if err := s.updateStatusAndNotify(ctx, order, domain.StatusCooking); err != nil {
+	if resume {
+		span.SetAttr("resumed", true)
+		s.logger.Info(ctx, "order_cooking_resumed", fmt.Sprintf("Resuming cooking of order %s", msg.OrderNumber), map[string]interface{}{"order": msg.OrderNumber})
+	} else if err := s.traceStage(ctx, "kitchen.start_cooking", func(ctx context.Context) error {
+		return s.updateStatusAndNotify(ctx, order, domain.StatusCooking)
+	}); err != nil {
+		if errors.Is(err, domain.ErrInvalidStatusTransition) {
+			// Заказ отменили до того, как мы его взяли
+			s.logger.Debug(ctx, "order_skipped", fmt.Sprintf("Order %s is no longer waiting", msg.OrderNumber), nil)
+			return nil
+		}
 		return err
 	}
 
 	// 3. Симуляция времени готовки
+	cookingStarted := time.Now()
 	cookingTime := order.GetCookingTime()
+	_, cookSpan := tracing.Start(ctx, "kitchen.cook", tracing.KindInternal)
+	cookSpan.SetAttr("cooking_time_ms", cookingTime.Milliseconds())
 	select {
-	case <-ctx.Done():
-		return ctx.Err()
+	case <-cookCtx.Done():
+		cookSpan.SetAttr("aborted", true)
+		cookSpan.End()
+		if errors.Is(context.Cause(cookCtx), domain.ErrWorkerDraining) {
+			return s.handBack(ctx, order)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.logger.Info(ctx, "order_cooking_aborted", fmt.Sprintf("Cooking of order %s aborted", msg.OrderNumber), map[string]interface{}{"order": msg.OrderNumber})
+		return nil
 	case <-time.After(cookingTime):
+		cookSpan.End()
 	}
 
 	// 4. Завершение готовки (Status: Ready)
-	if err := s.updateStatusAndNotify(ctx, order, domain.StatusReady); err != nil {
-		return err
+	if err := s.traceStage(ctx, "kitchen.finish", func(ctx context.Context) error {
+		return s.updateStatusAndNotify(ctx, order, domain.StatusReady)
+	}); err != nil {
+		if errors.Is(err, domain.ErrInvalidStatusTransition) {
+			// Отмена прошла, но уведомление до нас не дошло
+			s.logger.Info(ctx, "order_cooking_aborted", fmt.Sprintf("Order %s was cancelled during cooking", msg.OrderNumber), map[string]interface{}{"order": msg.OrderNumber})
+			return nil
+		}
+		return s.release(ctx, order, err)
 	}
+	metrics.CookingDuration.Observe(time.Since(cookingStarted).Seconds(), s.workerName)
 
 	// Обновляем счетчик обработанных заказов
 	if err := s.workerRepo.IncrementOrdersProcessed(ctx, s.workerName); err != nil {
-		s.logger.Error("db_error", "Failed to increment worker stats", "", nil, err)
+		s.logger.Error(ctx, "db_error", "Failed to increment worker stats", nil, err)
 	}
 
-	s.logger.Debug("order_completed", fmt.Sprintf("Order %s completed", msg.OrderNumber), "", nil)
+	s.logger.Debug(ctx, "order_completed", fmt.Sprintf("Order %s completed", msg.OrderNumber), nil)
 	return nil
 }
 
+// reportLoad записывает в workers число занятых слотов (заказов в готовке)
+func (s *Service) reportLoad(ctx context.Context) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	s.mu.Lock()
+	load := len(s.inFlight)
+	s.mu.Unlock()
+
+	if err := s.workerRepo.UpdateLoad(ctx, s.workerName, load); err != nil {
+		s.logger.Error(ctx, "db_error", "Failed to update worker load", map[string]interface{}{"load": load}, err)
+	}
+}
+
+// beginOrder учитывает заказ в active, если воркер не останавливается
+func (s *Service) beginOrder() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return false
+	}
+	s.active.Add(1)
+	return true
+}
+
+// handBack возвращает недоготовленный заказ в статус received, чтобы его взял другой воркер.
+// Возвращает ErrWorkerDraining, по которому consumer вернет сообщение в очередь.
+func (s *Service) handBack(ctx context.Context, order *domain.Order) error {
+	if err := s.updateStatusAndNotify(ctx, order, domain.StatusReceived); err != nil {
+		if errors.Is(err, domain.ErrInvalidStatusTransition) {
+			// Заказ успели отменить, возвращать нечего
+			return nil
+		}
+		return err
+	}
+
+	s.logger.Info(ctx, "order_handed_back", fmt.Sprintf("Order %s returned to the queue", order.Number), map[string]interface{}{
+		"order": order.Number,
+	})
+	return domain.ErrWorkerDraining
+}
+
+// release возвращает заказ в статус received после временного сбоя, чтобы повтор сообщения мог взять его заново.
+// Возвращает исходную ошибку, по которой consumer запланирует повтор. Если вернуть заказ не удалось,
+// он остается в cooking за этим воркером, и повтор возобновит готовку.
+func (s *Service) release(ctx context.Context, order *domain.Order, cause error) error {
+	if err := s.updateStatusAndNotify(ctx, order, domain.StatusReceived); err != nil {
+		if errors.Is(err, domain.ErrInvalidStatusTransition) {
+			// Заказ успели отменить, повторять нечего
+			return nil
+		}
+		s.logger.Error(ctx, "db_error", fmt.Sprintf("Failed to return order %s to the queue", order.Number), map[string]interface{}{
+			"order": order.Number,
+		}, err)
+	}
+	return cause
+}
+
+// traceStage выполняет этап обработки заказа в отдельном спане
+func (s *Service) traceStage(ctx context.Context, name string, stage func(context.Context) error) error {
+	ctx, span := tracing.Start(ctx, name, tracing.KindInternal)
+	defer span.End()
+
+	err := stage(ctx)
+	if !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		span.SetError(err)
+	}
+	return err
+}
+
+// AbortOrder прерывает готовку заказа, если этот воркер его сейчас готовит
+func (s *Service) AbortOrder(orderNumber string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	abort, ok := s.inFlight[orderNumber]
+	if ok {
+		abort(nil)
+	}
+	return ok
+}
+
+// trackOrder регистрирует готовку заказа; false - заказ уже готовится по другой доставке,
+// и ее запись (с функцией отмены) остается нетронутой
+func (s *Service) trackOrder(orderNumber string, abort context.CancelCauseFunc) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inFlight[orderNumber]; ok {
+		return false
+	}
+	s.inFlight[orderNumber] = abort
+
+	// Заказ загрузили уже после дедлайна остановки: сразу возвращаем его
+	if s.handingBack {
+		abort(domain.ErrWorkerDraining)
+	}
+	return true
+}
+
+func (s *Service) untrackOrder(orderNumber string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if abort, ok := s.inFlight[orderNumber]; ok {
+		abort(nil)
+		delete(s.inFlight, orderNumber)
+	}
+}
+
 func (s *Service) updateStatusAndNotify(ctx context.Context, order *domain.Order, newStatus domain.Status) error {
 	oldStatus := order.Status
+	previous := *order
 
 	// Обновляем в памяти
 	if err := order.TransitionTo(newStatus, s.workerName); err != nil {
 		return err
 	}
 
-	if err := s.orderRepo.UpdateStatusWithLog(ctx, order, newStatus, s.workerName); err != nil {
+	if err := s.orderRepo.UpdateStatusWithLog(ctx, order, newStatus, s.workerName, nil); err != nil {
+		// Заказ в памяти должен совпадать с БД, иначе следующий переход проверится не от того статуса
+		*order = previous
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
@@ -193,7 +481,7 @@ func (s *Service) updateStatusAndNotify(ctx context.Context, order *domain.Order
 	}
 
 	if err := s.publisher.PublishStatusUpdate(ctx, notification); err != nil {
-		s.logger.Error("rabbitmq_publish_failed", "Failed to publish status update", "", nil, err)
+		s.logger.Error(ctx, "rabbitmq_publish_failed", "Failed to publish status update", nil, err)
 		// Не блокируем процесс из-за ошибки уведомления
 	}
 
@@ -0,0 +1,121 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+type Service struct {
+	repo   interfaces.MenuRepository
+	logger logger.Logger
+}
+
+func NewService(repo interfaces.MenuRepository, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) ListMenu(ctx context.Context, includeUnavailable bool) ([]*domain.MenuItem, error) {
+	return s.repo.List(ctx, !includeUnavailable)
+}
+
+func (s *Service) GetItem(ctx context.Context, id int) (*domain.MenuItem, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *Service) CreateItem(ctx context.Context, cmd interfaces.MenuItemCommand) (*domain.MenuItem, error) {
+	item := &domain.MenuItem{
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Category:    cmd.Category,
+		Price:       cmd.Price,
+		Available:   cmd.Available,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := item.ReplaceModifierGroups(toModifierGroups(cmd.ModifierGroups)); err != nil {
+		return nil, err
+	}
+
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "menu_item_created", fmt.Sprintf("Menu item %s created", item.Name), map[string]interface{}{"menu_item_id": item.ID})
+
+	return item, nil
+}
+
+func (s *Service) UpdateItem(ctx context.Context, id int, cmd interfaces.MenuItemCommand) (*domain.MenuItem, error) {
+	item, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Name = cmd.Name
+	item.Description = cmd.Description
+	item.Category = cmd.Category
+	item.Price = cmd.Price
+	item.Available = cmd.Available
+	item.UpdatedAt = time.Now()
+	if cmd.ModifierGroups != nil {
+		if err := item.ReplaceModifierGroups(toModifierGroups(cmd.ModifierGroups)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, item); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "menu_item_updated", fmt.Sprintf("Menu item %s updated", item.Name), map[string]interface{}{"menu_item_id": item.ID})
+
+	return item, nil
+}
+
+func (s *Service) DeleteItem(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "menu_item_deleted", "Menu item deleted", map[string]interface{}{"menu_item_id": id})
+
+	return nil
+}
+
+func toModifierGroups(cmds []interfaces.ModifierGroupCommand) []domain.ModifierGroup {
+	groups := make([]domain.ModifierGroup, len(cmds))
+	for i, g := range cmds {
+		groups[i] = domain.ModifierGroup{
+			ID:        g.ID,
+			Name:      g.Name,
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			Modifiers: make([]domain.Modifier, len(g.Modifiers)),
+		}
+		for j, m := range g.Modifiers {
+			groups[i].Modifiers[j] = domain.Modifier{
+				ID:        m.ID,
+				Name:      m.Name,
+				Price:     m.Price,
+				Available: m.Available,
+			}
+		}
+	}
+	return groups
+}
@@ -2,62 +2,96 @@ package order
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/metrics"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
 	"github.com/YelzhanWeb/pizzas/internal/domain"
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 )
 
 type Service struct {
-	repo      interfaces.OrderRepository
-	publisher interfaces.MessagePublisher
-	logger    logger.Logger
+	repo         interfaces.OrderRepository
+	menuRepo     interfaces.MenuRepository
+	promoRepo    interfaces.PromotionRepository
+	publisher    interfaces.MessagePublisher
+	validator    *Validator
+	logger       logger.Logger
+	numberFormat domain.OrderNumberFormat
+	pricing      domain.PricingRules
 }
 
-func NewService(repo interfaces.OrderRepository, publisher interfaces.MessagePublisher, logger logger.Logger) *Service {
+func NewService(
+	repo interfaces.OrderRepository,
+	menuRepo interfaces.MenuRepository,
+	promoRepo interfaces.PromotionRepository,
+	publisher interfaces.MessagePublisher,
+	validator *Validator,
+	logger logger.Logger,
+	numberFormat domain.OrderNumberFormat,
+	pricing domain.PricingRules,
+) *Service {
 	return &Service{
-		repo:      repo,
-		publisher: publisher,
-		logger:    logger,
+		repo:         repo,
+		menuRepo:     menuRepo,
+		promoRepo:    promoRepo,
+		publisher:    publisher,
+		validator:    validator,
+		logger:       logger,
+		numberFormat: numberFormat,
+		pricing:      pricing,
 	}
 }
 
-func (s *Service) CreateOrder(ctx context.Context, cmd interfaces.CreateOrderCommand) (*domain.Order, error) {
-	// 1. Преобразование команд в доменные модели
-	items := make([]domain.OrderItem, len(cmd.Items))
-	for i, item := range cmd.Items {
-		items[i] = domain.OrderItem{
-			Name:     item.Name,
-			Quantity: item.Quantity,
-			Price:    item.Price,
-		}
-	}
+func (s *Service) CreateOrder(ctx context.Context, cmd interfaces.CreateOrderCommand) (_ *domain.Order, err error) {
+	ctx, span := tracing.Start(ctx, "order.create", tracing.KindInternal)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
 
-	orderType := domain.OrderType(cmd.OrderType)
-
-	// 2. Создание доменной сущности (здесь происходит валидация и расчет приоритета)
-	order, err := domain.NewOrder(cmd.CustomerName, orderType, items, cmd.TableNumber, cmd.DeliveryAddress)
-	if err != nil {
-		s.logger.Error("validation_failed", "Order validation failed", "", nil, err)
-		return nil, fmt.Errorf("validation failed: %w", err)
+	// 1. Нормализация текста (NFC, пробелы) и валидация команды: общие правила для всех точек входа
+	cmd = NormalizeCreateOrder(cmd)
+	if err := s.validator.ValidateCreateOrder(cmd); err != nil {
+		s.logger.Error(ctx, "validation_failed", "Order validation failed", nil, err)
+		return nil, err
 	}
 
-	// 3. Генерация номера заказа
-	number, err := s.repo.GenerateOrderNumber(ctx)
+	// 2. Преобразование команд в доменные модели: название и цена берутся из меню, а не от клиента
+	items, err := s.resolveItems(ctx, cmd.Items)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate order number: %w", err)
+		s.logger.Error(ctx, "validation_failed", "Order items validation failed", nil, err)
+		return nil, err
 	}
-	order.Number = number
 
-	// 4. Сохранение в БД (Транзакционно вместе с логами)
-	if err := s.repo.Create(ctx, order); err != nil {
-		s.logger.Error("db_transaction_failed", "Failed to create order", "", nil, err)
+	// 3. Создание доменной сущности с расчетом стоимости и приоритета; цены из меню проверяются по границам из конфига
+	order := domain.NewOrder(cmd.CustomerName, domain.OrderType(cmd.OrderType), items, cmd.TableNumber, cmd.DeliveryAddress, s.pricing)
+	if err := s.validator.ValidateOrder(order); err != nil {
+		s.logger.Error(ctx, "validation_failed", "Order validation failed", nil, err)
 		return nil, err
 	}
-	s.logger.Debug("order_received", "Order created in DB", "", map[string]interface{}{"order_number": order.Number})
 
-	// 5. Публикация сообщения в RabbitMQ
+	// 4. Промокод: скидка пересчитывает стоимость и приоритет заказа
+	if cmd.PromoCode != "" {
+		if err := s.applyPromotion(ctx, order, cmd.PromoCode); err != nil {
+			s.logger.Error(ctx, "validation_failed", "Promo code rejected", map[string]interface{}{"promo_code": cmd.PromoCode}, err)
+			return nil, err
+		}
+		order.Discount.CustomerKey = domain.PromotionCustomerKey(cmd.CustomerSubject, order.CustomerName)
+	}
+
+	// 5. Генерация номера заказа по дневному счетчику
+	seq, err := s.repo.NextOrderSequence(ctx, order.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate order number: %w", err)
+	}
+	order.Number = s.numberFormat.Format(order.CreatedAt, seq)
+
+	// 6. Подготовка сообщения для кухни
 	msg := interfaces.OrderMessage{
 		OrderNumber:     order.Number,
 		CustomerName:    order.CustomerName,
@@ -66,17 +100,192 @@ func (s *Service) CreateOrder(ctx context.Context, cmd interfaces.CreateOrderCom
 		DeliveryAddress: order.DeliveryAddress,
 		Items:           order.Items,
 		TotalAmount:     order.TotalAmount,
+		Currency:        order.Currency,
 		Priority:        order.Priority,
 	}
 
-	if err := s.publisher.PublishOrder(ctx, msg); err != nil {
-		s.logger.Error("rabbitmq_publish_failed", "Failed to publish order", "", nil, err)
-		// В реальной системе здесь могла бы быть логика Outbox Pattern или отката транзакции,
-		// но для учебного проекта вернем ошибку.
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal order message: %w", err)
+	}
+
+	event := &domain.OutboxEvent{
+		EventType: domain.OutboxEventOrderCreated,
+		Payload:   payload,
+		RequestID: logger.RequestID(ctx),
+	}
+	if sc, ok := tracing.SpanContextFromContext(ctx); ok {
+		event.Traceparent = sc.Traceparent()
+	}
+
+	// 7. Сохранение в БД вместе с событием outbox и использованием промокода (одна транзакция).
+	// Публикацию в RabbitMQ выполняет outbox relay, поэтому заказ не теряется при недоступности брокера.
+	if err := s.repo.Create(ctx, order, event); err != nil {
+		if errors.Is(err, domain.ErrPromotionLimitReached) {
+			return nil, promoCodeError(err)
+		}
+		s.logger.Error(ctx, "db_transaction_failed", "Failed to create order", nil, err)
+		return nil, err
+	}
+	s.logger.Debug(ctx, "order_received", "Order created in DB", map[string]interface{}{"order_number": order.Number})
+	span.SetAttr("order_number", order.Number)
+	metrics.OrdersCreated.Inc(string(order.Type))
+
+	return order, nil
+}
+
+// resolveItems сопоставляет позиции заказа с меню. Неизвестные и недоступные позиции
+// возвращаются как ошибки полей items[i].menu_item_id, неверный выбор модификаторов - items[i].modifier_ids.
+func (s *Service) resolveItems(ctx context.Context, cmdItems []interfaces.CreateOrderItemCommand) ([]domain.OrderItem, error) {
+	ids := make([]int, len(cmdItems))
+	for i, item := range cmdItems {
+		ids[i] = item.MenuItemID
+	}
+
+	menuItems, err := s.menuRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load menu: %w", err)
+	}
+
+	var errs domain.ValidationErrors
+	items := make([]domain.OrderItem, len(cmdItems))
+	for i, item := range cmdItems {
+		field := fmt.Sprintf("items[%d].menu_item_id", i)
+
+		menuItem, ok := menuItems[item.MenuItemID]
+		if !ok {
+			errs.Add(field, fmt.Sprintf("menu item %d does not exist", item.MenuItemID))
+			continue
+		}
+		if !menuItem.Available {
+			errs.Add(field, fmt.Sprintf("menu item %s is not available", menuItem.Name))
+			continue
+		}
+
+		modifiers, modErrs := menuItem.SelectModifiers(fmt.Sprintf("items[%d].modifier_ids", i), item.ModifierIDs)
+		if len(modErrs) > 0 {
+			errs = append(errs, modErrs...)
+			continue
+		}
+
+		for j := range modifiers {
+			modifiers[j].Price = modifiers[j].Price.In(s.pricing.Currency)
+		}
+
+		items[i] = domain.OrderItem{
+			MenuItemID: menuItem.ID,
+			Name:       menuItem.Name,
+			Quantity:   item.Quantity,
+			Price:      menuItem.Price.In(s.pricing.Currency),
+			Modifiers:  modifiers,
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return items, nil
+}
+
+// applyPromotion применяет промокод к заказу. Лимиты использования окончательно
+// проверяются при сохранении заказа, здесь отсекаются неизвестные и неподходящие коды.
+func (s *Service) applyPromotion(ctx context.Context, order *domain.Order, code string) error {
+	promotion, err := s.promoRepo.FindByCode(ctx, domain.NormalizePromoCode(code))
+	if errors.Is(err, domain.ErrPromotionNotFound) {
+		return promoCodeError(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load promotion: %w", err)
+	}
+
+	if err := order.ApplyPromotion(promotion, s.pricing, time.Now()); err != nil {
+		return promoCodeError(err)
+	}
+	return nil
+}
+
+// promoCodeError возвращает отказ по промокоду как ошибку поля promo_code
+func promoCodeError(err error) error {
+	var errs domain.ValidationErrors
+	errs.Add("promo_code", err.Error())
+	return errs
+}
+
+func (s *Service) CancelOrder(ctx context.Context, cmd interfaces.CancelOrderCommand) (*domain.Order, error) {
+	order, err := s.changeStatus(ctx, cmd.OrderNumber, domain.StatusCancelled, cmd.CancelledBy, func(*domain.Order) *string {
+		return withNote(cmd.Reason, cmd.Note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "order_cancelled", fmt.Sprintf("Order %s cancelled", order.Number), map[string]interface{}{
+		"order_number": order.Number,
+		"reason":       cmd.Reason,
+	})
+
+	return order, nil
+}
+
+// CompleteOrder фиксирует передачу готового заказа клиенту: подача в зале, выдача навынос или доставка
+func (s *Service) CompleteOrder(ctx context.Context, cmd interfaces.CompleteOrderCommand) (*domain.Order, error) {
+	order, err := s.changeStatus(ctx, cmd.OrderNumber, domain.StatusCompleted, cmd.CompletedBy, func(o *domain.Order) *string {
+		return withNote(o.HandoffAction(), cmd.Note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(ctx, "order_completed", fmt.Sprintf("Order %s %s", order.Number, order.HandoffAction()), map[string]interface{}{
+		"order_number": order.Number,
+		"completed_by": cmd.CompletedBy,
+	})
+
+	return order, nil
+}
+
+// withNote дописывает к комментарию журнала пометку из запроса (например, имя сотрудника)
+func withNote(text, note string) *string {
+	if note != "" {
+		text = fmt.Sprintf("%s (%s)", text, note)
+	}
+	return &text
+}
+
+// changeStatus переводит заказ в новый статус, пишет лог и отправляет уведомление.
+// notes вызывается после загрузки заказа и возвращает комментарий для order_status_log.
+func (s *Service) changeStatus(ctx context.Context, orderNumber string, newStatus domain.Status, changedBy string, notes func(*domain.Order) *string) (*domain.Order, error) {
+	order, err := s.repo.FindByNumber(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	oldStatus := order.Status
+
+	// processedBy не передаем, чтобы не затереть имя повара
+	if err := order.TransitionTo(newStatus, ""); err != nil {
 		return nil, err
 	}
 
-	s.logger.Debug("order_published", "Order published to RabbitMQ", "", map[string]interface{}{"order_number": order.Number})
+	if err := s.repo.UpdateStatusWithLog(ctx, order, newStatus, changedBy, notes(order)); err != nil {
+		s.logger.Error(ctx, "db_transaction_failed", "Failed to update order status", map[string]interface{}{"order_number": order.Number}, err)
+		return nil, err
+	}
+
+	// Кухня подписана на уведомления и прервет готовку отмененного заказа
+	notification := interfaces.StatusUpdateMessage{
+		OrderNumber: order.Number,
+		OldStatus:   oldStatus,
+		NewStatus:   newStatus,
+		ChangedBy:   changedBy,
+		Timestamp:   time.Now(),
+	}
+
+	if err := s.publisher.PublishStatusUpdate(ctx, notification); err != nil {
+		s.logger.Error(ctx, "rabbitmq_publish_failed", "Failed to publish status update", nil, err)
+		// Статус уже зафиксирован в БД, не откатываем его из-за уведомления
+	}
 
 	return order, nil
 }
@@ -1 +1,205 @@
 package order
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+// Limits - границы правил валидации заказа, задаются секцией validation в config.yaml
+type Limits struct {
+	CustomerNameMax    int
+	DeliveryAddressMin int
+	DeliveryAddressMax int
+	TableMin           int
+	TableMax           int
+	MaxItems           int
+	MaxQuantity        int
+	ItemNameMax        int
+	MinItemPrice       domain.Money
+	MaxItemPrice       domain.Money
+}
+
+// DefaultLimits возвращает ограничения, действовавшие до появления секции validation
+func DefaultLimits() Limits {
+	return Limits{
+		CustomerNameMax:    100,
+		DeliveryAddressMin: 10,
+		DeliveryAddressMax: 500,
+		TableMin:           1,
+		TableMax:           100,
+		MaxItems:           20,
+		MaxQuantity:        10,
+		ItemNameMax:        50,
+		MinItemPrice:       domain.MinItemPrice,
+		MaxItemPrice:       domain.MaxItemPrice,
+	}
+}
+
+// Разрешены: буквы любого алфавита (с диакритикой), пробелы, дефисы, апострофы
+var customerNameRegex = regexp.MustCompile(`^[\p{L}\p{M}\s\-'’]+$`)
+
+// Validator - единый набор правил валидации заказа для всех точек входа: HTTP, AMQP, импорт.
+// Все методы возвращают domain.ValidationErrors со всеми найденными ошибками полей, а не только первой.
+// Текст проверяется после нормализации (domain.NormalizeText), длина считается в символах, а не в байтах.
+type Validator struct {
+	limits Limits
+}
+
+func NewValidator(limits Limits) *Validator {
+	return &Validator{limits: limits}
+}
+
+// ValidateCreateOrder проверяет команду создания заказа до обращения к меню и БД
+func (v *Validator) ValidateCreateOrder(cmd interfaces.CreateOrderCommand) error {
+	var errs domain.ValidationErrors
+
+	v.checkCustomerName(&errs, cmd.CustomerName)
+	v.checkPlacement(&errs, domain.OrderType(cmd.OrderType), cmd.TableNumber, cmd.DeliveryAddress)
+	v.checkItemCount(&errs, len(cmd.Items))
+
+	for i, item := range cmd.Items {
+		if item.MenuItemID < 1 {
+			errs.Add(fmt.Sprintf("items[%d].menu_item_id", i), "menu item id is required")
+		}
+		v.checkQuantity(&errs, i, item.Quantity)
+		for _, id := range item.ModifierIDs {
+			if id < 1 {
+				errs.Add(fmt.Sprintf("items[%d].modifier_ids", i), "modifier ids must be positive")
+				break
+			}
+		}
+	}
+
+	return validationResult(errs)
+}
+
+// ValidateOrder проверяет собранный заказ: позиции уже сопоставлены с меню и имеют названия и цены
+func (v *Validator) ValidateOrder(order *domain.Order) error {
+	var errs domain.ValidationErrors
+	v.checkOrder(&errs, order)
+	return validationResult(errs)
+}
+
+// ValidateOrderMessage проверяет заказ, полученный из брокера, теми же правилами, что и HTTP
+func (v *Validator) ValidateOrderMessage(msg interfaces.OrderMessage) error {
+	var errs domain.ValidationErrors
+	if strings.TrimSpace(msg.OrderNumber) == "" {
+		errs.Add("order_number", "order number is required")
+	}
+	v.checkOrder(&errs, &domain.Order{
+		Number:          msg.OrderNumber,
+		CustomerName:    msg.CustomerName,
+		Type:            msg.OrderType,
+		TableNumber:     msg.TableNumber,
+		DeliveryAddress: msg.DeliveryAddress,
+		Items:           msg.Items,
+	})
+	return validationResult(errs)
+}
+
+func (v *Validator) checkOrder(errs *domain.ValidationErrors, order *domain.Order) {
+	v.checkCustomerName(errs, order.CustomerName)
+	v.checkPlacement(errs, order.Type, order.TableNumber, order.DeliveryAddress)
+	v.checkItemCount(errs, len(order.Items))
+
+	for i, item := range order.Items {
+		if length := domain.TextLength(domain.NormalizeText(item.Name)); length < 1 || length > v.limits.ItemNameMax {
+			errs.Add(fmt.Sprintf("items[%d].name", i), fmt.Sprintf("item name must be 1-%d characters", v.limits.ItemNameMax))
+		}
+		v.checkQuantity(errs, i, item.Quantity)
+		if item.Price.Cmp(v.limits.MinItemPrice) < 0 || item.Price.Cmp(v.limits.MaxItemPrice) > 0 {
+			errs.Add(fmt.Sprintf("items[%d].price", i), fmt.Sprintf("item price must be %s-%s", v.limits.MinItemPrice, v.limits.MaxItemPrice))
+		}
+	}
+}
+
+func (v *Validator) checkCustomerName(errs *domain.ValidationErrors, name string) {
+	name = domain.NormalizeText(name)
+	switch {
+	case name == "":
+		errs.Add("customer_name", "customer name is required")
+	case domain.TextLength(name) > v.limits.CustomerNameMax:
+		errs.Add("customer_name", fmt.Sprintf("customer name must not exceed %d characters", v.limits.CustomerNameMax))
+	case !customerNameRegex.MatchString(name):
+		errs.Add("customer_name", "customer name must contain only letters, spaces, hyphens, and apostrophes")
+	}
+}
+
+// checkPlacement проверяет поля, зависящие от типа заказа: стол только для dine_in,
+// адрес только для delivery, для takeout - ни того, ни другого
+func (v *Validator) checkPlacement(errs *domain.ValidationErrors, orderType domain.OrderType, tableNumber *int, deliveryAddress *string) {
+	switch orderType {
+	case domain.OrderTypeDineIn, domain.OrderTypeTakeout, domain.OrderTypeDelivery:
+	default:
+		errs.Add("order_type", "order type must be one of: dine_in, takeout, delivery")
+		return
+	}
+
+	if orderType == domain.OrderTypeDineIn {
+		if tableNumber == nil {
+			errs.Add("table_number", "table number is required for dine-in orders")
+		} else if *tableNumber < v.limits.TableMin || *tableNumber > v.limits.TableMax {
+			errs.Add("table_number", fmt.Sprintf("table number must be between %d and %d", v.limits.TableMin, v.limits.TableMax))
+		}
+	} else if tableNumber != nil {
+		errs.Add("table_number", fmt.Sprintf("table number must not be present for %s orders", orderTypeLabel(orderType)))
+	}
+
+	if orderType == domain.OrderTypeDelivery {
+		if deliveryAddress == nil {
+			errs.Add("delivery_address", "delivery address is required for delivery orders")
+		} else if length := domain.TextLength(domain.NormalizeText(*deliveryAddress)); length < v.limits.DeliveryAddressMin {
+			errs.Add("delivery_address", fmt.Sprintf("delivery address must be at least %d characters", v.limits.DeliveryAddressMin))
+		} else if length > v.limits.DeliveryAddressMax {
+			errs.Add("delivery_address", fmt.Sprintf("delivery address must not exceed %d characters", v.limits.DeliveryAddressMax))
+		}
+	} else if deliveryAddress != nil {
+		errs.Add("delivery_address", fmt.Sprintf("delivery address must not be present for %s orders", orderTypeLabel(orderType)))
+	}
+}
+
+func (v *Validator) checkItemCount(errs *domain.ValidationErrors, count int) {
+	if count < 1 {
+		errs.Add("items", "order must contain at least 1 item")
+	} else if count > v.limits.MaxItems {
+		errs.Add("items", fmt.Sprintf("order must not contain more than %d items", v.limits.MaxItems))
+	}
+}
+
+func (v *Validator) checkQuantity(errs *domain.ValidationErrors, index, quantity int) {
+	field := fmt.Sprintf("items[%d].quantity", index)
+	if quantity < 1 {
+		errs.Add(field, "item quantity must be at least 1")
+	} else if quantity > v.limits.MaxQuantity {
+		errs.Add(field, fmt.Sprintf("item quantity must not exceed %d", v.limits.MaxQuantity))
+	}
+}
+
+// NormalizeCreateOrder приводит текстовые поля команды к виду, в котором они сохраняются в БД
+func NormalizeCreateOrder(cmd interfaces.CreateOrderCommand) interfaces.CreateOrderCommand {
+	cmd.CustomerName = domain.NormalizeText(cmd.CustomerName)
+	if cmd.DeliveryAddress != nil {
+		address := domain.NormalizeText(*cmd.DeliveryAddress)
+		cmd.DeliveryAddress = &address
+	}
+	cmd.PromoCode = strings.TrimSpace(cmd.PromoCode)
+	return cmd
+}
+
+func orderTypeLabel(orderType domain.OrderType) string {
+	if orderType == domain.OrderTypeDineIn {
+		return "dine-in"
+	}
+	return string(orderType)
+}
+
+func validationResult(errs domain.ValidationErrors) error {
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
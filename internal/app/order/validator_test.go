@@ -0,0 +1,62 @@
+package order
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+func TestCheckCustomerName(t *testing.T) {
+	v := NewValidator(DefaultLimits())
+
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{"kazakh", "Әлия", false},
+		{"kazakh full name", "Жанна Қуанышқызы", false},
+		{"latin NFC", "José", false},
+		{"latin NFD", "Jose\u0301", false},
+		{"collapsed inner spaces", "Жанна   Қуанышқызы", false},
+		{"non-breaking space", "Жанна\u00a0Қуанышқызы", false},
+		{"hyphen and apostrophe", "Anne-Marie O'Neil", false},
+		{"100 kazakh letters", strings.Repeat("Қ", 100), false},
+		{"101 kazakh letters", strings.Repeat("Қ", 101), true},
+		{"100 NFD letters", strings.Repeat("e\u0301", 100), false},
+		{"101 NFD letters", strings.Repeat("e\u0301", 101), true},
+		{"empty", "", true},
+		{"only spaces", "   ", true},
+		{"digits", "Әлия 2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs domain.ValidationErrors
+			v.checkCustomerName(&errs, tt.in)
+			if gotErr := len(errs) > 0; gotErr != tt.wantErr {
+				t.Errorf("checkCustomerName(%q) errors = %v, wantErr %v", tt.in, errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeCreateOrderCustomerName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  Жанна   Қуанышқызы ", "Жанна Қуанышқызы"},
+		{"Jose\u0301", "José"},
+		{"Әлия", "Әлия"},
+	}
+
+	for _, tt := range tests {
+		cmd := NormalizeCreateOrder(interfaces.CreateOrderCommand{CustomerName: tt.in})
+		if cmd.CustomerName != tt.want {
+			t.Errorf("NormalizeCreateOrder(%q).CustomerName = %q, want %q", tt.in, cmd.CustomerName, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/adapter/tracing"
+	"github.com/YelzhanWeb/pizzas/internal/config"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+// Relay публикует события из таблицы order_outbox в RabbitMQ.
+// Событие помечается отправленным только после успешной публикации (at-least-once),
+// поэтому кухня должна обрабатывать повторы идемпотентно.
+type Relay struct {
+	repo         interfaces.OutboxRepository
+	publisher    interfaces.MessagePublisher
+	logger       logger.Logger
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+	maxBackoff   time.Duration
+}
+
+func NewRelay(repo interfaces.OutboxRepository, publisher interfaces.MessagePublisher, logger logger.Logger, cfg config.OutboxConfig) *Relay {
+	return &Relay{
+		repo:         repo,
+		publisher:    publisher,
+		logger:       logger,
+		pollInterval: time.Duration(cfg.PollIntervalMs) * time.Millisecond,
+		batchSize:    cfg.BatchSize,
+		lease:        time.Duration(cfg.LeaseSeconds) * time.Second,
+		maxBackoff:   time.Duration(cfg.MaxBackoffSeconds) * time.Second,
+	}
+}
+
+// Run опрашивает outbox до отмены контекста
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relayPending(ctx)
+		}
+	}
+}
+
+// relayPending отправляет события пачками, пока очередь не опустеет
+func (r *Relay) relayPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := r.repo.ClaimPending(ctx, r.batchSize, r.lease)
+		if err != nil {
+			r.logger.Error(ctx, "outbox_claim_failed", "Failed to claim outbox events", nil, err)
+			return
+		}
+
+		for _, event := range events {
+			r.relay(ctx, event)
+		}
+
+		if len(events) < r.batchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) relay(ctx context.Context, event *domain.OutboxEvent) {
+	// Запрос, создавший заказ, уже завершен; его идентификатор и трейс сохранены вместе с событием
+	ctx = logger.WithRequestID(ctx, event.RequestID)
+	if parent, ok := tracing.ParseTraceparent(event.Traceparent); ok {
+		ctx = tracing.WithRemoteParent(ctx, parent)
+	}
+
+	ctx, span := tracing.Start(ctx, "outbox.relay", tracing.KindInternal)
+	defer span.End()
+	span.SetAttr("outbox.event_id", event.ID)
+	span.SetAttr("outbox.attempts", event.Attempts+1)
+
+	err := r.publish(ctx, event)
+	if err == nil {
+		if err := r.repo.MarkSent(ctx, event.ID); err != nil {
+			// Событие будет отправлено повторно после истечения lease
+			r.logger.Error(ctx, "outbox_mark_failed", "Failed to mark outbox event as sent", map[string]interface{}{"event_id": event.ID}, err)
+			return
+		}
+		r.logger.Debug(ctx, "order_published", "Outbox event published to RabbitMQ", map[string]interface{}{
+			"event_id":   event.ID,
+			"event_type": event.EventType,
+			"order_id":   event.OrderID,
+		})
+		return
+	}
+
+	span.SetError(err)
+	nextAttempt := time.Now().Add(r.backoff(event.Attempts + 1))
+	r.logger.Error(ctx, "rabbitmq_publish_failed", "Failed to publish outbox event", map[string]interface{}{
+		"event_id":     event.ID,
+		"attempts":     event.Attempts + 1,
+		"next_attempt": nextAttempt,
+	}, err)
+
+	if err := r.repo.MarkFailed(ctx, event.ID, err.Error(), nextAttempt); err != nil {
+		r.logger.Error(ctx, "outbox_mark_failed", "Failed to record outbox failure", map[string]interface{}{"event_id": event.ID}, err)
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, event *domain.OutboxEvent) error {
+	switch event.EventType {
+	case domain.OutboxEventOrderCreated:
+		var msg interfaces.OrderMessage
+		if err := json.Unmarshal(event.Payload, &msg); err != nil {
+			return fmt.Errorf("failed to unmarshal order message: %w", err)
+		}
+		return r.publisher.PublishOrder(ctx, msg)
+	default:
+		return fmt.Errorf("unknown outbox event type: %s", event.EventType)
+	}
+}
+
+// backoff возвращает экспоненциальную задержку перед следующей попыткой
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	return delay
+}
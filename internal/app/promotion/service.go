@@ -0,0 +1,73 @@
+package promotion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
+	"github.com/YelzhanWeb/pizzas/internal/domain"
+	"github.com/YelzhanWeb/pizzas/internal/interfaces"
+)
+
+type Service struct {
+	repo   interfaces.PromotionRepository
+	logger logger.Logger
+}
+
+func NewService(repo interfaces.PromotionRepository, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) ListPromotions(ctx context.Context) ([]*domain.Promotion, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) CreatePromotion(ctx context.Context, cmd interfaces.PromotionCommand) (*domain.Promotion, error) {
+	promotion := &domain.Promotion{
+		Code:               domain.NormalizePromoCode(cmd.Code),
+		Description:        cmd.Description,
+		Kind:               cmd.Kind,
+		PercentBps:         cmd.PercentBps,
+		Amount:             cmd.Amount,
+		MenuItemID:         cmd.MenuItemID,
+		BuyQuantity:        cmd.BuyQuantity,
+		FreeQuantity:       cmd.FreeQuantity,
+		MinSubtotal:        cmd.MinSubtotal,
+		ValidFrom:          cmd.ValidFrom,
+		ValidTo:            cmd.ValidTo,
+		MaxUses:            cmd.MaxUses,
+		MaxUsesPerCustomer: cmd.MaxUsesPerCustomer,
+		Active:             true,
+		CreatedAt:          time.Now(),
+	}
+
+	if err := promotion.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "promotion_created", fmt.Sprintf("Promotion %s created", promotion.Code), map[string]interface{}{
+		"promotion_id": promotion.ID,
+		"kind":         promotion.Kind,
+	})
+
+	return promotion, nil
+}
+
+// DeactivatePromotion отключает промокод; история использований сохраняется
+func (s *Service) DeactivatePromotion(ctx context.Context, id int) error {
+	if err := s.repo.Deactivate(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "promotion_deactivated", "Promotion deactivated", map[string]interface{}{"promotion_id": id})
+
+	return nil
+}
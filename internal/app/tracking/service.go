@@ -2,6 +2,7 @@ package tracking
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/adapter/logger"
@@ -9,6 +10,11 @@ import (
 	"github.com/YelzhanWeb/pizzas/internal/interfaces"
 )
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
 type Service struct {
 	orderRepo  interfaces.OrderRepository
 	workerRepo interfaces.WorkerRepository
@@ -34,6 +40,7 @@ func (s *Service) GetOrderStatus(ctx context.Context, orderNumber string) (*inte
 		CurrentStatus: order.Status,
 		UpdatedAt:     order.UpdatedAt,
 		ProcessedBy:   order.ProcessedBy,
+		HandedOverAt:  order.HandedOverAt,
 	}
 
 	if order.Status == domain.StatusCooking {
@@ -68,13 +75,62 @@ func (s *Service) GetWorkersStatus(ctx context.Context) ([]*interfaces.TrackingW
 			status = domain.WorkerStatusOffline
 		}
 
+		// Пропавший без Shutdown воркер мог оставить в таблице ненулевую загрузку
+		load := w.CurrentLoad
+		if status == domain.WorkerStatusOffline {
+			load = 0
+		}
+
 		resp = append(resp, &interfaces.TrackingWorkerResponse{
 			WorkerName:      w.Name,
 			Status:          status,
 			OrdersProcessed: w.OrdersProcessed,
+			Capacity:        w.Capacity,
+			CurrentLoad:     load,
 			LastSeen:        w.LastSeen,
 		})
 	}
 
 	return resp, nil
 }
+
+// ListOrders возвращает страницу заказов и курсор следующей страницы
+func (s *Service) ListOrders(ctx context.Context, filter interfaces.OrderFilter) (*interfaces.TrackingOrderList, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit = limit + 1
+	orders, err := s.orderRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &interfaces.TrackingOrderList{Orders: orders}
+	if len(orders) > limit {
+		result.Orders = orders[:limit]
+		last := orders[limit-1]
+		result.NextCursor = &interfaces.OrderCursor{
+			SortValue: sortValue(last, filter.SortBy),
+			ID:        last.ID,
+		}
+	}
+
+	return result, nil
+}
+
+func sortValue(order *domain.Order, sortBy interfaces.OrderSortField) string {
+	switch sortBy {
+	case interfaces.OrderSortUpdatedAt:
+		return order.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case interfaces.OrderSortPriority:
+		return strconv.Itoa(int(order.Priority))
+	default:
+		return order.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
@@ -29,23 +29,199 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	cfg.applyDefaults()
+
 	return &cfg, nil
 }
 
+// applyEnv переопределяет секреты из переменных окружения, чтобы не хранить их в config.yaml
+func (c *Config) applyEnv() error {
+	if v, ok := os.LookupEnv("AUTH_ENABLED"); ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid AUTH_ENABLED: %w", err)
+		}
+		c.Auth.Enabled = enabled
+	}
+	if v, ok := os.LookupEnv("AUTH_API_KEYS"); ok {
+		c.Auth.APIKeys = v
+	}
+	if v, ok := os.LookupEnv("AUTH_JWT_SECRET"); ok {
+		c.Auth.JWTSecret = v
+	}
+	return nil
+}
+
+// applyDefaults заполняет необязательные параметры, которых нет в config.yaml
+func (c *Config) applyDefaults() {
+	if c.RabbitMQ.MaxAttempts <= 0 {
+		c.RabbitMQ.MaxAttempts = 5
+	}
+	if c.RabbitMQ.RetryBaseDelayMs <= 0 {
+		c.RabbitMQ.RetryBaseDelayMs = 1000
+	}
+	if c.RabbitMQ.RetryMaxDelayMs < c.RabbitMQ.RetryBaseDelayMs {
+		c.RabbitMQ.RetryMaxDelayMs = max(60000, c.RabbitMQ.RetryBaseDelayMs)
+	}
+	if c.Outbox.PollIntervalMs <= 0 {
+		c.Outbox.PollIntervalMs = 500
+	}
+	if c.Outbox.BatchSize <= 0 {
+		c.Outbox.BatchSize = 50
+	}
+	if c.Outbox.LeaseSeconds <= 0 {
+		c.Outbox.LeaseSeconds = 30
+	}
+	if c.Outbox.MaxBackoffSeconds <= 0 {
+		c.Outbox.MaxBackoffSeconds = 60
+	}
+	if c.Kitchen.DrainTimeoutSeconds <= 0 {
+		c.Kitchen.DrainTimeoutSeconds = 30
+	}
+
+	if c.OrderNumber.Prefix == "" {
+		c.OrderNumber.Prefix = "ORD"
+	}
+	if c.OrderNumber.Padding <= 0 {
+		c.OrderNumber.Padding = 3
+	}
+
+	if c.Money.Currency == "" {
+		c.Money.Currency = "USD"
+	}
+
+	if c.Admission.RetryAfterSeconds <= 0 {
+		c.Admission.RetryAfterSeconds = 5
+	}
+	if c.Admission.BacklogPollMs <= 0 {
+		c.Admission.BacklogPollMs = 1000
+	}
+
+	if c.RateLimit.RequestsPerSecond == "" {
+		c.RateLimit.RequestsPerSecond = "10"
+	}
+	if c.RateLimit.Burst <= 0 {
+		c.RateLimit.Burst = 20
+	}
+	if c.RateLimit.AuthFailuresPerMinute <= 0 {
+		c.RateLimit.AuthFailuresPerMinute = 10
+	}
+
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = "file"
+	}
+	if c.Tracing.File == "" {
+		c.Tracing.File = "traces.jsonl"
+	}
+
+	if c.Validation.CustomerNameMax <= 0 {
+		c.Validation.CustomerNameMax = 100
+	}
+	if c.Validation.DeliveryAddressMin <= 0 {
+		c.Validation.DeliveryAddressMin = 10
+	}
+	if c.Validation.DeliveryAddressMax <= 0 {
+		c.Validation.DeliveryAddressMax = 500
+	}
+	if c.Validation.TableMin <= 0 {
+		c.Validation.TableMin = 1
+	}
+	if c.Validation.TableMax <= 0 {
+		c.Validation.TableMax = 100
+	}
+	if c.Validation.MaxItems <= 0 {
+		c.Validation.MaxItems = 20
+	}
+	if c.Validation.MaxQuantity <= 0 {
+		c.Validation.MaxQuantity = 10
+	}
+	if c.Validation.MinItemPrice == "" {
+		c.Validation.MinItemPrice = "0.01"
+	}
+	if c.Validation.MaxItemPrice == "" {
+		c.Validation.MaxItemPrice = "999.99"
+	}
+}
+
+// RouteLimits разбирает per-route лимиты из строки Routes
+func (c RateLimitConfig) RouteLimits() ([]RouteLimitConfig, error) {
+	var limits []RouteLimitConfig
+	for _, spec := range strings.Split(c.Routes, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(spec, "=")
+		rate, burst, ok2 := strings.Cut(limit, ":")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("invalid route limit %q, expected [METHOD ]PREFIX=RPS:BURST", spec)
+		}
+
+		rl := RouteLimitConfig{Prefix: strings.TrimSpace(route)}
+		if method, prefix, found := strings.Cut(rl.Prefix, " "); found {
+			rl.Method = strings.ToUpper(method)
+			rl.Prefix = strings.TrimSpace(prefix)
+		}
+
+		var err error
+		if rl.RequestsPerSecond, err = strconv.ParseFloat(strings.TrimSpace(rate), 64); err != nil || rl.RequestsPerSecond < 0 {
+			return nil, fmt.Errorf("invalid rate in route limit %q", spec)
+		}
+		if rl.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || rl.Burst < 1 {
+			return nil, fmt.Errorf("invalid burst in route limit %q", spec)
+		}
+		if !strings.HasPrefix(rl.Prefix, "/") {
+			return nil, fmt.Errorf("route prefix must start with / in %q", spec)
+		}
+
+		limits = append(limits, rl)
+	}
+	return limits, nil
+}
+
+// Keys разбирает статические API-ключи из строки APIKeys
+func (c AuthConfig) Keys() ([]APIKeyConfig, error) {
+	var keys []APIKeyConfig
+	for i, spec := range strings.Split(c.APIKeys, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		// Сам ключ в ошибку не попадает
+		parts := strings.Split(spec, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid api key #%d, expected NAME:KEY:ROLE[|ROLE]", i+1)
+		}
+
+		keys = append(keys, APIKeyConfig{
+			Name:  strings.TrimSpace(parts[0]),
+			Key:   strings.TrimSpace(parts[1]),
+			Roles: strings.Split(parts[2], "|"),
+		})
+	}
+	return keys, nil
+}
+
 func parseYAML(data string) (map[string]any, error) {
 	lines := strings.Split(data, "\n")
 	result := make(map[string]any)
 
 	var currentSection string
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	for _, rawLine := range lines {
+		line := strings.TrimSpace(rawLine)
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
-		// Секция: "database:"
-		if strings.HasSuffix(line, ":") && !strings.Contains(line, " ") {
+		// Секция: "database:" (без отступа, чтобы пустые значения вроде "location_code:" не считались секцией)
+		indented := strings.HasPrefix(rawLine, " ") || strings.HasPrefix(rawLine, "\t")
+		if !indented && strings.HasSuffix(line, ":") && !strings.Contains(line, " ") {
 			currentSection = strings.TrimSuffix(line, ":")
 			result[currentSection] = make(map[string]any)
 			continue
@@ -59,11 +235,13 @@ func parseYAML(data string) (map[string]any, error) {
 			}
 
 			key := strings.TrimSpace(parts[0])
-			val := strings.TrimSpace(parts[1])
+			val := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
 
-			// Попытка конвертации в число
+			// Попытка конвертации в число или bool
 			if n, err := strconv.Atoi(val); err == nil {
 				result[currentSection].(map[string]any)[key] = n
+			} else if val == "true" || val == "false" {
+				result[currentSection].(map[string]any)[key] = val == "true"
 			} else {
 				result[currentSection].(map[string]any)[key] = val
 			}
@@ -1,8 +1,20 @@
 package config
 
+import "encoding/json"
+
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	RabbitMQ RabbitMQConfig `yaml:"rabbitmq"`
+	Database    DatabaseConfig    `yaml:"database"`
+	RabbitMQ    RabbitMQConfig    `yaml:"rabbitmq"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Kitchen     KitchenConfig     `yaml:"kitchen"`
+	OrderNumber OrderNumberConfig `yaml:"order_number" json:"order_number"`
+	Money       MoneyConfig       `yaml:"money"`
+	Pricing     PricingConfig     `yaml:"pricing"`
+	Admission   AdmissionConfig   `yaml:"admission"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" json:"rate_limit"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Validation  ValidationConfig  `yaml:"validation"`
+	Tracing     TracingConfig     `yaml:"tracing"`
 }
 
 type DatabaseConfig struct {
@@ -18,4 +30,102 @@ type RabbitMQConfig struct {
 	Port     int    `yaml:"port"`
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
+	// MaxPriority - уровни приоритета очередей кухни (x-max-priority, 1-255), 0 - очередь без приоритетов
+	MaxPriority int `yaml:"max_priority" json:"max_priority"`
+	// MaxAttempts - сколько раз кухня пробует обработать заказ, прежде чем отправить его в DLQ.
+	// Между попытками заказ ждет в очереди задержки: RetryBaseDelayMs, дальше вдвое дольше, но не больше RetryMaxDelayMs.
+	MaxAttempts      int `yaml:"max_attempts" json:"max_attempts"`
+	RetryBaseDelayMs int `yaml:"retry_base_delay_ms" json:"retry_base_delay_ms"`
+	RetryMaxDelayMs  int `yaml:"retry_max_delay_ms" json:"retry_max_delay_ms"`
+}
+
+type OutboxConfig struct {
+	PollIntervalMs    int `yaml:"poll_interval_ms" json:"poll_interval_ms"`
+	BatchSize         int `yaml:"batch_size" json:"batch_size"`
+	LeaseSeconds      int `yaml:"lease_seconds" json:"lease_seconds"`
+	MaxBackoffSeconds int `yaml:"max_backoff_seconds" json:"max_backoff_seconds"`
+}
+
+type KitchenConfig struct {
+	// DrainTimeoutSeconds - сколько при остановке ждать заказы в готовке, прежде чем вернуть их в очередь
+	DrainTimeoutSeconds int `yaml:"drain_timeout_seconds" json:"drain_timeout_seconds"`
+}
+
+type OrderNumberConfig struct {
+	Prefix       string `yaml:"prefix"`
+	LocationCode string `yaml:"location_code" json:"location_code"`
+	Padding      int    `yaml:"padding"`
+}
+
+type MoneyConfig struct {
+	Currency string `yaml:"currency"`
+}
+
+type PricingConfig struct {
+	// Taxes: "NAME:RATE_BPS" через запятую, например "VAT:1200" (12%)
+	Taxes            string      `yaml:"taxes"`
+	ServiceChargeBps int         `yaml:"service_charge_bps" json:"service_charge_bps"`
+	DeliveryFee      json.Number `yaml:"delivery_fee" json:"delivery_fee"`
+}
+
+type AdmissionConfig struct {
+	RetryAfterSeconds int `yaml:"retry_after_seconds" json:"retry_after_seconds"`
+	MaxKitchenBacklog int `yaml:"max_kitchen_backlog" json:"max_kitchen_backlog"` // 0 - не проверять очередь кухни
+	BacklogPollMs     int `yaml:"backlog_poll_ms" json:"backlog_poll_ms"`
+}
+
+type RateLimitConfig struct {
+	Enabled           bool        `yaml:"enabled"`
+	RequestsPerSecond json.Number `yaml:"requests_per_second" json:"requests_per_second"`
+	Burst             int         `yaml:"burst"`
+	// Routes: "[METHOD ]PREFIX=RPS:BURST" через запятую, например "POST /orders=1:5,/orders/=5:10".
+	// Выбирается правило с самым длинным совпавшим префиксом, RPS 0 отключает лимит для маршрута.
+	Routes            string `yaml:"routes"`
+	TrustForwardedFor bool   `yaml:"trust_forwarded_for" json:"trust_forwarded_for"`
+	// AuthFailuresPerMinute - сколько запросов с неверными учетными данными (ответ 401) допускается с одного IP в минуту
+	AuthFailuresPerMinute int `yaml:"auth_failures_per_minute" json:"auth_failures_per_minute"`
+}
+
+// RouteLimitConfig - лимит для маршрута из RateLimitConfig.Routes
+type RouteLimitConfig struct {
+	Method            string
+	Prefix            string
+	RequestsPerSecond float64
+	Burst             int
+}
+
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// APIKeys: "NAME:KEY:ROLE[|ROLE]" через запятую, например "kiosk-1:secret:cashier"
+	APIKeys   string `yaml:"api_keys" json:"api_keys"`
+	JWTSecret string `yaml:"jwt_secret" json:"jwt_secret"`
+	JWTIssuer string `yaml:"jwt_issuer" json:"jwt_issuer"`
+}
+
+// APIKeyConfig - статический API-ключ из AuthConfig.APIKeys
+type APIKeyConfig struct {
+	Name  string
+	Key   string
+	Roles []string
+}
+
+// ValidationConfig - границы правил валидации заказа; нулевые значения заменяются значениями по умолчанию
+type ValidationConfig struct {
+	CustomerNameMax    int         `yaml:"customer_name_max" json:"customer_name_max"`
+	DeliveryAddressMin int         `yaml:"delivery_address_min" json:"delivery_address_min"`
+	DeliveryAddressMax int         `yaml:"delivery_address_max" json:"delivery_address_max"`
+	TableMin           int         `yaml:"table_min" json:"table_min"`
+	TableMax           int         `yaml:"table_max" json:"table_max"`
+	MaxItems           int         `yaml:"max_items" json:"max_items"`
+	MaxQuantity        int         `yaml:"max_quantity" json:"max_quantity"`
+	MinItemPrice       json.Number `yaml:"min_item_price" json:"min_item_price"`
+	MaxItemPrice       json.Number `yaml:"max_item_price" json:"max_item_price"`
+}
+
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Exporter: "file" - JSON lines в File, "http" - POST пачек спанов на Endpoint
+	Exporter string `yaml:"exporter"`
+	File     string `yaml:"file"`
+	Endpoint string `yaml:"endpoint"`
 }
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Role grants access to a group of HTTP endpoints
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleCashier  Role = "cashier"
+	RoleKitchen  Role = "kitchen"
+	RoleAdmin    Role = "admin"
+)
+
+// ParseRole validates a role name from config or a token
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleCustomer, RoleCashier, RoleKitchen, RoleAdmin:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role: %q", s)
+	}
+}
+
+// Principal is an authenticated API client: a static API key or a JWT subject
+type Principal struct {
+	Subject string
+	Roles   []Role
+}
+
+// HasAnyRole checks the principal's roles; admin is allowed everything
+func (p *Principal) HasAnyRole(roles ...Role) bool {
+	for _, have := range p.Roles {
+		if have == RoleAdmin {
+			return true
+		}
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrTokenExpired    = errors.New("token expired")
+)
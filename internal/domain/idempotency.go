@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// IdempotencyRecord stores the outcome of a request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	Key          string
+	RequestHash  string
+	Completed    bool
+	ResponseCode int
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+// IsExpired checks if the key is old enough to be reused
+func (r *IdempotencyRecord) IsExpired(ttl time.Duration) bool {
+	return time.Since(r.CreatedAt) > ttl
+}
+
+var (
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
+	ErrIdempotencyKeyReclaimed  = errors.New("idempotency key lease expired and was taken by another request")
+)
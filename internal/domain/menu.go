@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MenuItem represents a dish in the menu catalog
+type MenuItem struct {
+	ID          int
+	Name        string
+	Description string
+	Category    string
+	Price       Money
+	Available   bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	ModifierGroups []ModifierGroup
+}
+
+// ModifierGroup is a set of options for a menu item, e.g. size, crust or toppings
+type ModifierGroup struct {
+	ID         int
+	MenuItemID int
+	Name       string
+	MinSelect  int
+	MaxSelect  int
+	Modifiers  []Modifier
+}
+
+// Modifier is a single option inside a modifier group with its price surcharge
+type Modifier struct {
+	ID        int
+	GroupID   int
+	Name      string
+	Price     Money
+	Available bool
+}
+
+// ReplaceModifierGroups sets the item's modifier groups. Groups and modifiers with an ID keep it
+// and must already belong to the item (a modifier to the same group); the ones left out are removed.
+func (m *MenuItem) ReplaceModifierGroups(groups []ModifierGroup) error {
+	existing := make(map[int]map[int]bool, len(m.ModifierGroups))
+	for _, group := range m.ModifierGroups {
+		mods := make(map[int]bool, len(group.Modifiers))
+		for _, mod := range group.Modifiers {
+			mods[mod.ID] = true
+		}
+		existing[group.ID] = mods
+	}
+
+	var errs ValidationErrors
+	seenGroups := make(map[int]bool, len(groups))
+	seenMods := make(map[int]bool)
+	for i, group := range groups {
+		prefix := fmt.Sprintf("modifier_groups[%d]", i)
+
+		// A new group (ID 0) may only hold new modifiers
+		mods, ok := existing[group.ID]
+		if group.ID != 0 && (!ok || seenGroups[group.ID]) {
+			errs.Add(prefix+".id", "modifier group does not belong to this menu item")
+			continue
+		}
+		seenGroups[group.ID] = true
+
+		for j, mod := range group.Modifiers {
+			if mod.ID == 0 {
+				continue
+			}
+			if !mods[mod.ID] || seenMods[mod.ID] {
+				errs.Add(fmt.Sprintf("%s.modifiers[%d].id", prefix, j), "modifier does not belong to this group")
+			}
+			seenMods[mod.ID] = true
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	m.ModifierGroups = groups
+	return nil
+}
+
+// Validate applies catalog validation rules
+func (m *MenuItem) Validate() error {
+	var errs ValidationErrors
+
+	if len(m.Name) < 1 || len(m.Name) > 50 {
+		errs.Add("name", "name must be 1-50 characters")
+	}
+	if len(m.Description) > 500 {
+		errs.Add("description", "description must not exceed 500 characters")
+	}
+	if len(m.Category) > 50 {
+		errs.Add("category", "category must not exceed 50 characters")
+	}
+	if m.Price.Cmp(MinItemPrice) < 0 || m.Price.Cmp(MaxItemPrice) > 0 {
+		errs.Add("price", "price must be 0.01-999.99")
+	}
+
+	for i, group := range m.ModifierGroups {
+		prefix := fmt.Sprintf("modifier_groups[%d]", i)
+
+		if len(group.Name) < 1 || len(group.Name) > 50 {
+			errs.Add(prefix+".name", "group name must be 1-50 characters")
+		}
+		if group.MinSelect < 0 || group.MaxSelect < 1 || group.MinSelect > group.MaxSelect {
+			errs.Add(prefix+".max_select", "selection limits must satisfy 0 <= min_select <= max_select and max_select >= 1")
+		}
+		if len(group.Modifiers) < 1 {
+			errs.Add(prefix+".modifiers", "group must have at least 1 modifier")
+		} else if group.MinSelect > len(group.Modifiers) {
+			errs.Add(prefix+".min_select", "min_select must not exceed the number of modifiers")
+		}
+
+		for j, mod := range group.Modifiers {
+			modPrefix := fmt.Sprintf("%s.modifiers[%d]", prefix, j)
+			if len(mod.Name) < 1 || len(mod.Name) > 50 {
+				errs.Add(modPrefix+".name", "modifier name must be 1-50 characters")
+			}
+			if mod.Price.IsNegative() || mod.Price.Cmp(MaxItemPrice) > 0 {
+				errs.Add(modPrefix+".price", "modifier price must be 0-999.99")
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SelectModifiers checks the chosen modifier ids against the item's groups and selection rules.
+// Errors are reported under the given field name.
+func (m *MenuItem) SelectModifiers(field string, ids []int) ([]OrderItemModifier, ValidationErrors) {
+	var errs ValidationErrors
+
+	type choice struct {
+		group    *ModifierGroup
+		modifier *Modifier
+	}
+	byID := make(map[int]choice)
+	for gi := range m.ModifierGroups {
+		group := &m.ModifierGroups[gi]
+		for mi := range group.Modifiers {
+			byID[group.Modifiers[mi].ID] = choice{group: group, modifier: &group.Modifiers[mi]}
+		}
+	}
+
+	selected := make([]OrderItemModifier, 0, len(ids))
+	perGroup := make(map[int]int)
+	seen := make(map[int]bool)
+
+	for _, id := range ids {
+		c, ok := byID[id]
+		if !ok {
+			errs.Add(field, fmt.Sprintf("modifier %d is not offered for %s", id, m.Name))
+			continue
+		}
+		if seen[id] {
+			errs.Add(field, fmt.Sprintf("modifier %s is selected more than once", c.modifier.Name))
+			continue
+		}
+		if !c.modifier.Available {
+			errs.Add(field, fmt.Sprintf("modifier %s is not available", c.modifier.Name))
+			continue
+		}
+
+		seen[id] = true
+		perGroup[c.group.ID]++
+		selected = append(selected, OrderItemModifier{
+			ModifierID: c.modifier.ID,
+			Group:      c.group.Name,
+			Name:       c.modifier.Name,
+			Price:      c.modifier.Price,
+		})
+	}
+
+	for _, group := range m.ModifierGroups {
+		count := perGroup[group.ID]
+		if count < group.MinSelect {
+			errs.Add(field, fmt.Sprintf("%s: select at least %d", group.Name, group.MinSelect))
+		} else if count > group.MaxSelect {
+			errs.Add(field, fmt.Sprintf("%s: select at most %d", group.Name, group.MaxSelect))
+		}
+	}
+
+	return selected, errs
+}
+
+var (
+	ErrMenuItemNotFound = errors.New("menu item not found")
+	// ErrMenuItemInUse: an item-specific promotion refers to the menu item
+	ErrMenuItemInUse = errors.New("menu item is used by a promotion")
+)
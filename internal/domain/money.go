@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact monetary amount stored as integer minor units (cents) with a currency code.
+//
+// Rounding rules:
+//   - ParseMoney and JSON input keep two decimal places; extra digits are rounded half away from zero
+//     (0.005 -> 0.01, -0.005 -> -0.01).
+//   - Percent rounds the result to minor units half away from zero.
+//   - Add, Sub and Mul are exact.
+//
+// The zero value is 0.00 without a currency. Arithmetic keeps the receiver's currency,
+// or takes the other operand's currency if the receiver has none.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+const minorPerUnit = 100
+
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// NewMoney creates an amount from minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// ParseMoney parses a decimal string such as "19.99", "-3" or "1e2"
+func ParseMoney(s, currency string) (Money, error) {
+	text := strings.TrimSpace(s)
+	// big.Rat also accepts fractions ("1/3") and base prefixes ("0x10"), which are not amounts
+	if strings.Trim(text, "0123456789.eE+-") != "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	r.Mul(r, big.NewRat(minorPerUnit, 1))
+
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, s)
+	}
+
+	minor := q.Int64()
+	if r.Sign() < 0 {
+		minor = -minor
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO currency code
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// In returns the same amount tagged with the given currency
+func (m Money) In(currency string) Money {
+	return Money{minor: m.minor, currency: currency}
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{minor: m.minor + other.minor, currency: m.pickCurrency(other)}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{minor: m.minor - other.minor, currency: m.pickCurrency(other)}
+}
+
+// Mul multiplies the amount by an integer quantity
+func (m Money) Mul(qty int) Money {
+	return Money{minor: m.minor * int64(qty), currency: m.currency}
+}
+
+// Percent returns the given share of the amount in basis points (1250 = 12.5%)
+func (m Money) Percent(basisPoints int64) Money {
+	return Money{minor: divRound(m.minor*basisPoints, 10000), currency: m.currency}
+}
+
+// Cmp compares amounts: -1 if m < other, 0 if equal, 1 if m > other
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m.minor < other.minor:
+		return -1
+	case m.minor > other.minor:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// String formats the amount as a decimal without currency, e.g. "19.99"
+func (m Money) String() string {
+	sign := ""
+	minor := m.minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/minorPerUnit, minor%minorPerUnit)
+}
+
+// MarshalJSON writes the amount as a JSON number (19.99), as the API did with float64
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a decimal string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParseMoney(s, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) pickCurrency(other Money) string {
+	if m.currency != "" {
+		return m.currency
+	}
+	return other.currency
+}
+
+// divRound divides rounding half away from zero
+func divRound(a, b int64) int64 {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= b {
+		if a < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
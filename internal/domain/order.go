@@ -2,6 +2,8 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -14,30 +16,69 @@ type Order struct {
 	TableNumber     *int
 	DeliveryAddress *string
 	Items           []OrderItem
-	TotalAmount     float64
+	TotalAmount     Money
+	Currency        string
+	Pricing         PriceBreakdown
+	Discount        Discount
 	Priority        Priority
 	Status          Status
 	ProcessedBy     *string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	CompletedAt     *time.Time
+	HandedOverAt    *time.Time
 }
 
 // OrderItem represents an item in an order
 type OrderItem struct {
-	ID       int
-	OrderID  int
-	Name     string
-	Quantity int
-	Price    float64
+	ID         int
+	OrderID    int
+	MenuItemID int
+	Name       string
+	Quantity   int
+	Price      Money
+	Modifiers  []OrderItemModifier
 }
 
-// NewOrder creates a new order with business rules applied
-func NewOrder(customerName string, orderType OrderType, items []OrderItem, tableNumber *int, deliveryAddress *string) (*Order, error) {
+// OrderItemModifier is a modifier chosen for an order item, copied from the menu at order time
+type OrderItemModifier struct {
+	ModifierID int
+	Group      string
+	Name       string
+	Price      Money
+}
+
+// UnitPrice returns the item price including modifier surcharges
+func (i OrderItem) UnitPrice() Money {
+	price := i.Price
+	for _, mod := range i.Modifiers {
+		price = price.Add(mod.Price)
+	}
+	return price
+}
+
+// Description returns a human-readable line for the kitchen, e.g. "2x Pepperoni (Large, Thin crust)"
+func (i OrderItem) Description() string {
+	line := fmt.Sprintf("%dx %s", i.Quantity, i.Name)
+	if len(i.Modifiers) == 0 {
+		return line
+	}
+
+	names := make([]string, len(i.Modifiers))
+	for j, mod := range i.Modifiers {
+		names[j] = mod.Name
+	}
+	return line + " (" + strings.Join(names, ", ") + ")"
+}
+
+// NewOrder creates a new order and prices it with the given rules.
+// Input rules are checked beforehand by the order validator.
+func NewOrder(customerName string, orderType OrderType, items []OrderItem, tableNumber *int, deliveryAddress *string, pricing PricingRules) *Order {
 	order := &Order{
 		CustomerName:    customerName,
 		Type:            orderType,
 		Items:           items,
+		Currency:        pricing.Currency,
 		TableNumber:     tableNumber,
 		DeliveryAddress: deliveryAddress,
 		Status:          StatusReceived,
@@ -45,71 +86,45 @@ func NewOrder(customerName string, orderType OrderType, items []OrderItem, table
 		UpdatedAt:       time.Now(),
 	}
 
-	if err := order.Validate(); err != nil {
-		return nil, err
-	}
-
-	order.CalculateTotal()
+	order.CalculateTotal(pricing)
 	order.DeterminePriority()
 
-	return order, nil
+	return order
 }
 
-// Validate applies business validation rules
-func (o *Order) Validate() error {
-	if len(o.CustomerName) < 1 || len(o.CustomerName) > 100 {
-		return errors.New("customer name must be 1-100 characters")
-	}
-
-	if o.Type != OrderTypeDineIn && o.Type != OrderTypeTakeout && o.Type != OrderTypeDelivery {
-		return errors.New("invalid order type")
-	}
-
-	if o.Type == OrderTypeDineIn && o.TableNumber == nil {
-		return errors.New("table number required for dine-in orders")
-	}
-
-	if o.Type == OrderTypeDineIn && (o.TableNumber != nil && (*o.TableNumber < 1 || *o.TableNumber > 100)) {
-		return errors.New("table number must be between 1 and 100")
-	}
-
-	if o.Type == OrderTypeDelivery && (o.DeliveryAddress == nil || len(*o.DeliveryAddress) < 10) {
-		return errors.New("delivery address required (min 10 characters)")
-	}
-
-	if len(o.Items) < 1 || len(o.Items) > 20 {
-		return errors.New("order must have 1-20 items")
-	}
-
+// Subtotal returns the sum of item prices including modifiers
+func (o *Order) Subtotal() Money {
+	subtotal := NewMoney(0, o.Currency)
 	for _, item := range o.Items {
-		if len(item.Name) < 1 || len(item.Name) > 50 {
-			return errors.New("item name must be 1-50 characters")
-		}
-		if item.Quantity < 1 || item.Quantity > 10 {
-			return errors.New("item quantity must be 1-10")
-		}
-		if item.Price < 0.01 || item.Price > 999.99 {
-			return errors.New("item price must be 0.01-999.99")
-		}
+		subtotal = subtotal.Add(item.UnitPrice().Mul(item.Quantity))
 	}
+	return subtotal
+}
 
-	return nil
+// CalculateTotal prices the order: subtotal, discount, service charge, delivery fee and taxes
+func (o *Order) CalculateTotal(rules PricingRules) {
+	o.Pricing = rules.Price(o.Type, o.Subtotal(), o.Discount)
+	o.TotalAmount = o.Pricing.Total
 }
 
-// CalculateTotal calculates the total amount of the order
-func (o *Order) CalculateTotal() {
-	total := 0.0
-	for _, item := range o.Items {
-		total += item.Price * float64(item.Quantity)
+// ApplyPromotion reprices the order with a promotion discount and updates its priority
+func (o *Order) ApplyPromotion(promotion *Promotion, rules PricingRules, now time.Time) error {
+	discount, err := promotion.DiscountFor(o, now)
+	if err != nil {
+		return err
 	}
-	o.TotalAmount = total
+
+	o.Discount = discount
+	o.CalculateTotal(rules)
+	o.DeterminePriority()
+	return nil
 }
 
-// DeterminePriority determines the priority based on total amount
+// DeterminePriority determines the priority based on the grand total
 func (o *Order) DeterminePriority() {
-	if o.TotalAmount > 100 {
+	if o.TotalAmount.Cmp(highPriorityTotal) > 0 {
 		o.Priority = PriorityHigh
-	} else if o.TotalAmount >= 50 {
+	} else if o.TotalAmount.Cmp(mediumPriorityTotal) >= 0 {
 		o.Priority = PriorityMedium
 	} else {
 		o.Priority = PriorityLow
@@ -134,14 +149,20 @@ func (o *Order) TransitionTo(newStatus Status, processedBy string) error {
 		o.CompletedAt = &now
 	}
 
+	if newStatus == StatusCompleted {
+		now := time.Now()
+		o.HandedOverAt = &now
+	}
+
 	return nil
 }
 
 // CanTransitionTo checks if the order can transition to the new status
 func (o *Order) CanTransitionTo(newStatus Status) bool {
+	// Cooking -> Received: a worker shutting down mid-cook hands the order back to the queue
 	validTransitions := map[Status][]Status{
 		StatusReceived:  {StatusCooking, StatusCancelled},
-		StatusCooking:   {StatusReady, StatusCancelled},
+		StatusCooking:   {StatusReady, StatusCancelled, StatusReceived},
 		StatusReady:     {StatusCompleted, StatusCancelled},
 		StatusCompleted: {},
 		StatusCancelled: {},
@@ -156,6 +177,20 @@ func (o *Order) CanTransitionTo(newStatus Status) bool {
 	return false
 }
 
+// HandoffAction describes how a ready order reaches the customer
+func (o *Order) HandoffAction() string {
+	switch o.Type {
+	case OrderTypeDineIn:
+		return "served"
+	case OrderTypeTakeout:
+		return "picked_up"
+	case OrderTypeDelivery:
+		return "delivered"
+	default:
+		return "completed"
+	}
+}
+
 // GetCookingTime returns the cooking time based on order type
 func (o *Order) GetCookingTime() time.Duration {
 	switch o.Type {
@@ -170,7 +205,17 @@ func (o *Order) GetCookingTime() time.Duration {
 	}
 }
 
+// Price bounds and priority thresholds in minor units
+var (
+	MinItemPrice        = NewMoney(1, "")
+	MaxItemPrice        = NewMoney(99999, "")
+	highPriorityTotal   = NewMoney(10000, "")
+	mediumPriorityTotal = NewMoney(5000, "")
+)
+
 var (
 	ErrInvalidStatusTransition = errors.New("invalid status transition")
 	ErrInvalidOrderType        = errors.New("invalid order type")
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrMalformedOrderMessage   = errors.New("malformed order message")
 )
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OrderNumberFormat describes how order numbers are built, e.g. ORD_ALM1_20240115_001
+type OrderNumberFormat struct {
+	Prefix       string
+	LocationCode string
+	Padding      int
+}
+
+// Format builds the order number for the given day and daily sequence value.
+// Sequences wider than the padding are printed in full, so numbers keep working past 999 orders a day.
+func (f OrderNumberFormat) Format(day time.Time, seq int64) string {
+	parts := []string{f.Prefix}
+	if f.LocationCode != "" {
+		parts = append(parts, f.LocationCode)
+	}
+	parts = append(parts, day.UTC().Format("20060102"), fmt.Sprintf("%0*d", f.Padding, seq))
+	return strings.Join(parts, "_")
+}
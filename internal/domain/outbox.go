@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+const (
+	OutboxEventOrderCreated = "order.created"
+)
+
+// OutboxEvent represents a message stored in the outbox until it is published
+type OutboxEvent struct {
+	ID          int64
+	OrderID     int
+	EventType   string
+	Payload     []byte
+	RequestID   string
+	Traceparent string
+	Attempts    int
+	CreatedAt   time.Time
+}
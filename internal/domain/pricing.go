@@ -0,0 +1,77 @@
+package domain
+
+// TaxRule is a named tax rate in basis points (1200 = 12%)
+type TaxRule struct {
+	Name    string
+	RateBps int64
+}
+
+// PricingRules configure how an order subtotal becomes the amount charged.
+// The discount is taken off the subtotal before the service charge; taxes are calculated on
+// subtotal - discount + service charge + delivery fee, each line rounded separately.
+type PricingRules struct {
+	Currency         string
+	Taxes            []TaxRule
+	ServiceChargeBps int64 // dine-in only
+	DeliveryFee      Money // delivery only
+}
+
+// TaxLine is a tax amount charged on an order
+type TaxLine struct {
+	Name    string
+	RateBps int64
+	Amount  Money
+}
+
+// PriceBreakdown is the itemised price of an order.
+// Discount includes a waived delivery fee, so Total = Subtotal - Discount + ServiceCharge + DeliveryFee + taxes.
+type PriceBreakdown struct {
+	Subtotal      Money
+	Discount      Money
+	PromoCode     string
+	ServiceCharge Money
+	DeliveryFee   Money
+	Taxes         []TaxLine
+	Total         Money
+}
+
+// Price calculates the breakdown for an order type, items subtotal and promotion discount
+func (r PricingRules) Price(orderType OrderType, subtotal Money, discount Discount) PriceBreakdown {
+	zero := NewMoney(0, r.Currency)
+	p := PriceBreakdown{
+		Subtotal:      subtotal.In(r.Currency),
+		Discount:      zero,
+		PromoCode:     discount.Code,
+		ServiceCharge: zero,
+		DeliveryFee:   zero,
+	}
+
+	itemsDiscount := discount.Items.In(r.Currency)
+	if itemsDiscount.Cmp(p.Subtotal) > 0 {
+		itemsDiscount = p.Subtotal
+	}
+	if !itemsDiscount.IsNegative() {
+		p.Discount = itemsDiscount
+	}
+	discounted := p.Subtotal.Sub(p.Discount)
+
+	switch orderType {
+	case OrderTypeDineIn:
+		p.ServiceCharge = discounted.Percent(r.ServiceChargeBps)
+	case OrderTypeDelivery:
+		p.DeliveryFee = r.DeliveryFee.In(r.Currency)
+		if discount.FreeDelivery {
+			p.Discount = p.Discount.Add(p.DeliveryFee)
+		}
+	}
+
+	base := p.Subtotal.Add(p.ServiceCharge).Add(p.DeliveryFee).Sub(p.Discount)
+	p.Total = base
+	for _, tax := range r.Taxes {
+		line := TaxLine{Name: tax.Name, RateBps: tax.RateBps, Amount: base.Percent(tax.RateBps)}
+		p.Taxes = append(p.Taxes, line)
+		p.Total = p.Total.Add(line.Amount)
+	}
+
+	return p
+}
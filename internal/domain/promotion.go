@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type PromotionKind string
+
+const (
+	PromotionPercentage   PromotionKind = "percentage"
+	PromotionFixedAmount  PromotionKind = "fixed_amount"
+	PromotionBuyXGetY     PromotionKind = "buy_x_get_y"
+	PromotionFreeDelivery PromotionKind = "free_delivery"
+)
+
+// Promotion is a promo code with its discount rule, validity window and usage limits
+type Promotion struct {
+	ID          int
+	Code        string
+	Description string
+	Kind        PromotionKind
+
+	PercentBps   int64 // percentage: share of the items subtotal in basis points
+	Amount       Money // fixed_amount: amount taken off the items subtotal
+	MenuItemID   int   // buy_x_get_y: qualifying menu item, 0 means any item
+	BuyQuantity  int   // buy_x_get_y: units to pay for
+	FreeQuantity int   // buy_x_get_y: units given for free
+	MinSubtotal  Money
+
+	ValidFrom          *time.Time
+	ValidTo            *time.Time
+	MaxUses            *int
+	MaxUsesPerCustomer *int
+	TimesUsed          int
+	Active             bool
+	CreatedAt          time.Time
+}
+
+// Discount is the reduction granted to an order by a promotion
+type Discount struct {
+	PromotionID  int
+	Code         string
+	Items        Money // taken off the items subtotal
+	FreeDelivery bool
+	CustomerKey  string // who max_uses_per_customer is counted against, see PromotionCustomerKey
+}
+
+// PromotionCustomerKey identifies the customer for max_uses_per_customer. An authenticated customer
+// is counted by subject. Orders taken by staff fall back to the customer name with case, punctuation
+// and spacing ignored: that only deters casual reuse, since any other spelling counts as a new customer.
+func PromotionCustomerKey(customerSubject, customerName string) string {
+	if customerSubject != "" {
+		return "principal:" + customerSubject
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(NormalizeText(customerName)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// NormalizePromoCode makes promo codes case-insensitive
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate applies promotion validation rules
+func (p *Promotion) Validate() error {
+	var errs ValidationErrors
+
+	if len(p.Code) < 3 || len(p.Code) > 32 || strings.ContainsAny(p.Code, " \t") {
+		errs.Add("code", "code must be 3-32 characters without spaces")
+	}
+	if len(p.Description) > 200 {
+		errs.Add("description", "description must not exceed 200 characters")
+	}
+
+	switch p.Kind {
+	case PromotionPercentage:
+		if p.PercentBps < 1 || p.PercentBps > 10000 {
+			errs.Add("percent_bps", "percent_bps must be 1-10000")
+		}
+	case PromotionFixedAmount:
+		if p.Amount.Cmp(MinItemPrice) < 0 || p.Amount.Cmp(MaxItemPrice) > 0 {
+			errs.Add("amount", "amount must be 0.01-999.99")
+		}
+	case PromotionBuyXGetY:
+		if p.BuyQuantity < 1 || p.FreeQuantity < 1 {
+			errs.Add("buy_quantity", "buy_quantity and free_quantity must be at least 1")
+		}
+		if p.MenuItemID < 0 {
+			errs.Add("menu_item_id", "menu_item_id must not be negative")
+		}
+	case PromotionFreeDelivery:
+	default:
+		errs.Add("kind", "kind must be one of: percentage, fixed_amount, buy_x_get_y, free_delivery")
+	}
+
+	if p.MinSubtotal.IsNegative() {
+		errs.Add("min_subtotal", "min_subtotal must not be negative")
+	}
+	if p.ValidFrom != nil && p.ValidTo != nil && !p.ValidTo.After(*p.ValidFrom) {
+		errs.Add("valid_to", "valid_to must be after valid_from")
+	}
+	if p.MaxUses != nil && *p.MaxUses < 1 {
+		errs.Add("max_uses", "max_uses must be at least 1")
+	}
+	if p.MaxUsesPerCustomer != nil && *p.MaxUsesPerCustomer < 1 {
+		errs.Add("max_uses_per_customer", "max_uses_per_customer must be at least 1")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DiscountFor checks that the promotion applies to the order and calculates its discount.
+// Usage limits are enforced by the repository when the order is saved.
+func (p *Promotion) DiscountFor(order *Order, now time.Time) (Discount, error) {
+	switch {
+	case !p.Active:
+		return Discount{}, ErrPromotionInactive
+	case p.ValidFrom != nil && now.Before(*p.ValidFrom):
+		return Discount{}, ErrPromotionNotStarted
+	case p.ValidTo != nil && !now.Before(*p.ValidTo):
+		return Discount{}, ErrPromotionExpired
+	case p.MaxUses != nil && p.TimesUsed >= *p.MaxUses:
+		return Discount{}, ErrPromotionLimitReached
+	}
+
+	subtotal := order.Subtotal()
+	if subtotal.Cmp(p.MinSubtotal) < 0 {
+		return Discount{}, fmt.Errorf("%w: order subtotal must be at least %s", ErrPromotionNotApplicable, p.MinSubtotal)
+	}
+
+	discount := Discount{PromotionID: p.ID, Code: p.Code, Items: NewMoney(0, order.Currency)}
+
+	switch p.Kind {
+	case PromotionPercentage:
+		discount.Items = subtotal.Percent(p.PercentBps)
+	case PromotionFixedAmount:
+		discount.Items = p.Amount.In(order.Currency)
+		if discount.Items.Cmp(subtotal) > 0 {
+			discount.Items = subtotal
+		}
+	case PromotionBuyXGetY:
+		discount.Items = p.freeUnitsValue(order)
+		if discount.Items.IsZero() {
+			return Discount{}, fmt.Errorf("%w: add %d+%d qualifying items", ErrPromotionNotApplicable, p.BuyQuantity, p.FreeQuantity)
+		}
+	case PromotionFreeDelivery:
+		if order.Type != OrderTypeDelivery {
+			return Discount{}, fmt.Errorf("%w: free delivery applies to delivery orders only", ErrPromotionNotApplicable)
+		}
+		discount.FreeDelivery = true
+	}
+
+	return discount, nil
+}
+
+// freeUnitsValue returns the price of free units; qualifying units are pooled
+// across order lines and the cheapest ones are free
+func (p *Promotion) freeUnitsValue(order *Order) Money {
+	var units []Money
+	for _, item := range order.Items {
+		if p.MenuItemID != 0 && item.MenuItemID != p.MenuItemID {
+			continue
+		}
+		for i := 0; i < item.Quantity; i++ {
+			units = append(units, item.UnitPrice())
+		}
+	}
+
+	sort.Slice(units, func(i, j int) bool { return units[i].Cmp(units[j]) < 0 })
+
+	free := len(units) / (p.BuyQuantity + p.FreeQuantity) * p.FreeQuantity
+	total := NewMoney(0, order.Currency)
+	for _, price := range units[:free] {
+		total = total.Add(price)
+	}
+	return total
+}
+
+var (
+	ErrPromotionNotFound      = errors.New("promo code not found")
+	ErrPromotionInactive      = errors.New("promo code is not active")
+	ErrPromotionNotStarted    = errors.New("promo code is not valid yet")
+	ErrPromotionExpired       = errors.New("promo code has expired")
+	ErrPromotionLimitReached  = errors.New("promo code usage limit reached")
+	ErrPromotionNotApplicable = errors.New("promo code does not apply to this order")
+	ErrPromotionCodeExists    = errors.New("promo code already exists")
+)
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type OrderType string
 
@@ -10,6 +14,36 @@ const (
 	OrderTypeDelivery OrderType = "delivery"
 )
 
+// OrderTypes lists every order type; each one has its own kitchen queue
+func OrderTypes() []OrderType {
+	return []OrderType{OrderTypeDineIn, OrderTypeTakeout, OrderTypeDelivery}
+}
+
+// ParseOrderTypes parses a comma-separated list such as "dine_in,takeout"; an empty list means all types
+func ParseOrderTypes(s string) ([]OrderType, error) {
+	var types []OrderType
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		t := OrderType(part)
+		known := false
+		for _, candidate := range OrderTypes() {
+			if t == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOrderType, part)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
 type Status string
 
 const (
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const zeroWidthJoiner = '\u200d'
+
+// NormalizeText converts free-form input to NFC, trims it and collapses inner whitespace,
+// so "José  Garcia" and "José Garcia" are stored identically
+func NormalizeText(s string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(s)), " ")
+}
+
+// TextLength counts user-perceived characters (extended grapheme clusters) rather than bytes:
+// combining marks, emoji modifiers and zero-width-joiner sequences extend the preceding character,
+// a pair of regional indicators (a flag) counts once, CR LF counts once and conjoining Hangul jamo
+// form one syllable. Rarer rules such as prepended concatenation marks are not applied.
+func TextLength(s string) int {
+	count := 0
+	prev := jamoNone
+	afterCR := false
+	joined := false
+	pendingFlag := false
+
+	for _, r := range s {
+		jamo := hangulJamo(r)
+
+		switch {
+		case r == '\n' && afterCR:
+			afterCR = false
+			continue
+		case afterCR:
+			// Nothing extends a control character
+		case unicode.Is(unicode.M, r), isEmojiModifier(r):
+			prev = jamoNone
+			pendingFlag = false
+			continue
+		case r == zeroWidthJoiner:
+			joined = true
+			continue
+		case joined:
+			joined = false
+			pendingFlag = false
+			prev = jamo
+			continue
+		case jamo != jamoNone && jamoExtends(prev, jamo):
+			prev = jamo
+			continue
+		case isRegionalIndicator(r):
+			if pendingFlag {
+				pendingFlag = false
+				continue
+			}
+			pendingFlag = true
+			prev = jamoNone
+			afterCR = false
+			count++
+			continue
+		}
+
+		pendingFlag = false
+		joined = false
+		prev = jamo
+		afterCR = r == '\r'
+		count++
+	}
+
+	return count
+}
+
+// jamoKind classifies Hangul by the Hangul_Syllable_Type property
+type jamoKind int
+
+const (
+	jamoNone jamoKind = iota
+	jamoL             // leading consonant
+	jamoV             // vowel
+	jamoT             // trailing consonant
+	jamoLV            // precomposed syllable without a trailing consonant
+	jamoLVT           // precomposed syllable with a trailing consonant
+)
+
+func hangulJamo(r rune) jamoKind {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return jamoL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return jamoV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return jamoT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return jamoLV
+		}
+		return jamoLVT
+	default:
+		return jamoNone
+	}
+}
+
+// jamoExtends reports whether next continues the syllable started by prev (rules GB6-GB8 of UAX #29)
+func jamoExtends(prev, next jamoKind) bool {
+	switch prev {
+	case jamoL:
+		return next == jamoL || next == jamoV || next == jamoLV || next == jamoLVT
+	case jamoLV, jamoV:
+		return next == jamoV || next == jamoT
+	case jamoLVT, jamoT:
+		return next == jamoT
+	default:
+		return false
+	}
+}
+
+func isEmojiModifier(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+// Строки с диакритикой записаны escape-последовательностями: иначе редактор может молча
+// привести NFD к NFC, и тест перестанет проверять нормализацию
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"ascii", "Anna", "Anna"},
+		{"trims and collapses spaces", "  José   Garcia \t", "José Garcia"},
+		{"accented latin NFD to NFC", "Jose\u0301", "José"},
+		{"accented latin NFC unchanged", "José", "José"},
+		{"cyrillic", "Анна  Иванова", "Анна Иванова"},
+		{"cyrillic short i NFD to NFC", "И\u0306ра", "Йра"},
+		{"kazakh", " Жанна   Қуанышқызы ", "Жанна Қуанышқызы"},
+		{"kazakh letters without decomposition", "Әлия", "Әлия"},
+		{"non-breaking and ideographic spaces", "Әлия\u00a0\u3000Серік", "Әлия Серік"},
+		{"only spaces", " \t\n ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.in); got != tt.want {
+				t.Errorf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "Anna", 4},
+		{"cyrillic", "Анна", 4},
+		{"kazakh", "Қуанышқызы", 10},
+		{"kazakh schwa", "Әлия", 4},
+		{"accented latin NFC", "José", 4},
+		{"accented latin NFD", "Jose\u0301", 4},
+		{"cyrillic short i NFD", "И\u0306", 1},
+		{"stacked combining marks", "e\u0323\u0301", 1},
+		{"emoji with skin tone", "\U0001F44D\U0001F3FD", 1},
+		{"zwj family", "\U0001F469\u200d\U0001F469\u200d\U0001F467", 1},
+		{"flag", "\U0001F1F0\U0001F1FF", 1},
+		{"two flags", "\U0001F1F0\U0001F1FF\U0001F1EB\U0001F1F7", 2},
+		{"three regional indicators", "\U0001F1F0\U0001F1FF\U0001F1EB", 2},
+		{"regional indicators split by a mark", "\U0001F1F0\u0301\U0001F1FF", 2},
+		{"emoji with variation selector", "\u2764\ufe0f", 1},
+		{"crlf", "a\r\nb", 3},
+		{"lf cr is two characters", "\n\r", 2},
+		{"mark after cr starts a character", "\r\u0301", 2},
+		{"hangul precomposed", "한국", 2},
+		{"hangul jamo L V T", "\u1112\u1161\u11ab", 1},
+		{"hangul jamo L V", "\u1100\u1161", 1},
+		{"hangul jamo L L V", "\u1100\u1100\u1161", 1},
+		{"hangul LV syllable takes a trailing T", "가\u11a8", 1},
+		{"hangul LVT syllable does not take a vowel", "각\u1161", 2},
+		{"hangul mark ends the syllable", "\u1100\u0301\u1161", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TextLength(tt.in); got != tt.want {
+				t.Errorf("TextLength(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextLengthCountsCharactersNotBytes(t *testing.T) {
+	name := strings.Repeat("Қ", 100)
+	if len(name) != 200 {
+		t.Fatalf("expected 2-byte runes, got %d bytes", len(name))
+	}
+	if got := TextLength(name); got != 100 {
+		t.Errorf("TextLength = %d, want 100", got)
+	}
+}
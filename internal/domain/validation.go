@@ -0,0 +1,25 @@
+package domain
+
+import "strings"
+
+// FieldError describes a validation problem with a single input field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors collects field-level validation problems
+type ValidationErrors []FieldError
+
+// Add appends a field error
+func (e *ValidationErrors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Field + ": " + fe.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
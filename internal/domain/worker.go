@@ -13,7 +13,10 @@ type Worker struct {
 	Status          WorkerStatus
 	LastSeen        time.Time
 	OrdersProcessed int
-	CreatedAt       time.Time
+	// Capacity is the number of oven slots, CurrentLoad how many of them are busy
+	Capacity    int
+	CurrentLoad int
+	CreatedAt   time.Time
 }
 
 type WorkerStatus string
@@ -23,6 +26,13 @@ const (
 	WorkerStatusOffline WorkerStatus = "offline"
 )
 
+var (
+	// ErrWorkerDraining is returned for orders a stopping worker did not finish; they go back to the queue
+	ErrWorkerDraining = errors.New("worker is shutting down")
+	// ErrUnsupportedOrderType is returned by a specialised worker for an order type it does not cook
+	ErrUnsupportedOrderType = errors.New("order type is not handled by this worker")
+)
+
 // NewWorker creates a new worker
 func NewWorker(name, workerType string) (*Worker, error) {
 	if name == "" {
@@ -34,6 +44,7 @@ func NewWorker(name, workerType string) (*Worker, error) {
 		Type:      workerType,
 		Status:    WorkerStatusOnline,
 		LastSeen:  time.Now(),
+		Capacity:  1,
 		CreatedAt: time.Now(),
 	}, nil
 }
@@ -47,6 +58,7 @@ func (w *Worker) UpdateHeartbeat() {
 // SetOffline marks the worker as offline
 func (w *Worker) SetOffline() {
 	w.Status = WorkerStatusOffline
+	w.CurrentLoad = 0
 }
 
 // IncrementOrdersProcessed increments the orders processed count
@@ -15,7 +15,8 @@ type OrderMessage struct {
 	TableNumber     *int               `json:"table_number"`
 	DeliveryAddress *string            `json:"delivery_address"`
 	Items           []domain.OrderItem `json:"items"`
-	TotalAmount     float64            `json:"total_amount"`
+	TotalAmount     domain.Money       `json:"total_amount"`
+	Currency        string             `json:"currency,omitempty"`
 	Priority        domain.Priority    `json:"priority"`
 }
 
@@ -35,12 +36,72 @@ type CreateOrderCommand struct {
 	TableNumber     *int
 	DeliveryAddress *string
 	Items           []CreateOrderItemCommand
+	PromoCode       string
+	// CustomerSubject - аутентифицированный клиент, оформляющий заказ сам; пусто для заказов, принятых персоналом
+	CustomerSubject string
 }
 
 type CreateOrderItemCommand struct {
-	Name     string
-	Quantity int
-	Price    float64
+	MenuItemID  int
+	Quantity    int
+	ModifierIDs []int
+}
+
+type MenuItemCommand struct {
+	Name        string
+	Description string
+	Category    string
+	Price       domain.Money
+	Available   bool
+	// ModifierGroups == nil оставляет группы позиции без изменений
+	ModifierGroups []ModifierGroupCommand
+}
+
+// ModifierGroupCommand с ID обновляет существующую группу, без ID - создает новую
+type ModifierGroupCommand struct {
+	ID        int
+	Name      string
+	MinSelect int
+	MaxSelect int
+	Modifiers []ModifierCommand
+}
+
+type ModifierCommand struct {
+	ID        int
+	Name      string
+	Price     domain.Money
+	Available bool
+}
+
+type PromotionCommand struct {
+	Code               string
+	Description        string
+	Kind               domain.PromotionKind
+	PercentBps         int64
+	Amount             domain.Money
+	MenuItemID         int
+	BuyQuantity        int
+	FreeQuantity       int
+	MinSubtotal        domain.Money
+	ValidFrom          *time.Time
+	ValidTo            *time.Time
+	MaxUses            *int
+	MaxUsesPerCustomer *int
+}
+
+// CancelOrderCommand: CancelledBy - аутентифицированный автор, Note - необязательная пометка из запроса
+type CancelOrderCommand struct {
+	OrderNumber string
+	Reason      string
+	CancelledBy string
+	Note        string
+}
+
+// CompleteOrderCommand: CompletedBy - аутентифицированный автор, Note - необязательная пометка из запроса
+type CompleteOrderCommand struct {
+	OrderNumber string
+	CompletedBy string
+	Note        string
 }
 
 // Интерфейсы Messaging (Adapter/RabbitMQ)
@@ -49,9 +110,16 @@ type MessagePublisher interface {
 	PublishStatusUpdate(ctx context.Context, msg StatusUpdateMessage) error
 }
 
+// QueueInspector читает состояние очередей брокера
+type QueueInspector interface {
+	QueueDepth(ctx context.Context, queue string) (int, error)
+}
+
 type MessageConsumer interface {
 	ConsumeOrders(ctx context.Context, handler OrderMessageHandler) error
 	ConsumeNotifications(ctx context.Context, handler NotificationHandler) error
+	// Consuming сообщает, что все запущенные циклы получения подписаны на свои очереди
+	Consuming() bool
 }
 
 type (
@@ -2,19 +2,50 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/YelzhanWeb/pizzas/internal/domain"
 )
 
 // Интерфейсы Репозиториев (Adapter/Postgres)
 type OrderRepository interface {
-	Create(ctx context.Context, order *domain.Order) error
+	Create(ctx context.Context, order *domain.Order, event *domain.OutboxEvent) error
 	FindByNumber(ctx context.Context, number string) (*domain.Order, error)
-	GenerateOrderNumber(ctx context.Context) (string, error)
+	NextOrderSequence(ctx context.Context, day time.Time) (int64, error)
 	Update(ctx context.Context, order *domain.Order) error
 	LogStatus(ctx context.Context, orderID int, status domain.Status, changedBy string) error
 	GetStatusHistory(ctx context.Context, orderID int) ([]*domain.StatusLog, error)
-	UpdateStatusWithLog(ctx context.Context, order *domain.Order, status domain.Status, changedBy string) error
+	UpdateStatusWithLog(ctx context.Context, order *domain.Order, status domain.Status, changedBy string, notes *string) error
+	List(ctx context.Context, filter OrderFilter) ([]*domain.Order, error)
+}
+
+type OrderSortField string
+
+const (
+	OrderSortCreatedAt OrderSortField = "created_at"
+	OrderSortUpdatedAt OrderSortField = "updated_at"
+	OrderSortPriority  OrderSortField = "priority"
+)
+
+// OrderFilter описывает поиск заказов с keyset-пагинацией
+type OrderFilter struct {
+	Statuses     []domain.Status
+	Types        []domain.OrderType
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	CustomerName string
+	ProcessedBy  string
+	Priority     *domain.Priority
+	SortBy       OrderSortField
+	Descending   bool
+	Limit        int
+	After        *OrderCursor
+}
+
+// OrderCursor указывает на последний заказ предыдущей страницы
+type OrderCursor struct {
+	SortValue string
+	ID        int
 }
 
 type WorkerRepository interface {
@@ -22,6 +53,38 @@ type WorkerRepository interface {
 	FindByName(ctx context.Context, name string) (*domain.Worker, error)
 	Update(ctx context.Context, worker *domain.Worker) error
 	UpdateHeartbeat(ctx context.Context, name string) error
+	UpdateLoad(ctx context.Context, name string, load int) error
 	ListAll(ctx context.Context) ([]*domain.Worker, error)
 	IncrementOrdersProcessed(ctx context.Context, name string) error
 }
+
+type OutboxRepository interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, reason string, nextAttempt time.Time) error
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, principal, key, token, requestHash string, lease time.Duration) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, principal, key, token string, responseCode int, responseBody []byte) error
+	Release(ctx context.Context, principal, key, token string) error
+	DeleteExpired(ctx context.Context, principal, key string, createdBefore time.Time) error
+}
+
+type MenuRepository interface {
+	List(ctx context.Context, onlyAvailable bool) ([]*domain.MenuItem, error)
+	FindByID(ctx context.Context, id int) (*domain.MenuItem, error)
+	FindByIDs(ctx context.Context, ids []int) (map[int]*domain.MenuItem, error)
+	Create(ctx context.Context, item *domain.MenuItem) error
+	Update(ctx context.Context, item *domain.MenuItem) error
+	Delete(ctx context.Context, id int) error
+}
+
+// PromotionRepository хранит промокоды. Лимиты использования проверяются атомарно
+// в OrderRepository.Create вместе с сохранением заказа.
+type PromotionRepository interface {
+	FindByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	List(ctx context.Context) ([]*domain.Promotion, error)
+	Create(ctx context.Context, promotion *domain.Promotion) error
+	Deactivate(ctx context.Context, id int) error
+}
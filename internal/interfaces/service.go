@@ -10,18 +10,56 @@ import (
 // Интерфейсы Сервисов (Business Logic)
 type OrderService interface {
 	CreateOrder(ctx context.Context, cmd CreateOrderCommand) (*domain.Order, error)
+	CancelOrder(ctx context.Context, cmd CancelOrderCommand) (*domain.Order, error)
+	CompleteOrder(ctx context.Context, cmd CompleteOrderCommand) (*domain.Order, error)
+}
+
+// OrderValidator - общие правила валидации заказа для всех точек входа.
+// Ошибки возвращаются как domain.ValidationErrors.
+type OrderValidator interface {
+	ValidateCreateOrder(cmd CreateOrderCommand) error
+	ValidateOrderMessage(msg OrderMessage) error
+}
+
+type MenuService interface {
+	ListMenu(ctx context.Context, includeUnavailable bool) ([]*domain.MenuItem, error)
+	GetItem(ctx context.Context, id int) (*domain.MenuItem, error)
+	CreateItem(ctx context.Context, cmd MenuItemCommand) (*domain.MenuItem, error)
+	UpdateItem(ctx context.Context, id int, cmd MenuItemCommand) (*domain.MenuItem, error)
+	DeleteItem(ctx context.Context, id int) error
+}
+
+type PromotionService interface {
+	ListPromotions(ctx context.Context) ([]*domain.Promotion, error)
+	CreatePromotion(ctx context.Context, cmd PromotionCommand) (*domain.Promotion, error)
+	DeactivatePromotion(ctx context.Context, id int) error
+}
+
+// KitchenBacklog сообщает о переполнении очереди кухни
+type KitchenBacklog interface {
+	Saturated() bool
+	Depth() int
+}
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, principal, key, requestHash string) (record *domain.IdempotencyRecord, token string, err error)
+	Complete(ctx context.Context, principal, key, token string, responseCode int, responseBody []byte) error
+	Release(ctx context.Context, principal, key, token string) error
 }
 
 type KitchenService interface {
 	Start(ctx context.Context) error
+	Drain(ctx context.Context) error
 	Shutdown(ctx context.Context) error
 	ProcessOrder(ctx context.Context, msg OrderMessage) error
+	AbortOrder(orderNumber string) bool
 }
 
 type TrackingService interface {
 	GetOrderStatus(ctx context.Context, orderNumber string) (*TrackingOrderResponse, error)
 	GetOrderHistory(ctx context.Context, orderNumber string) ([]*domain.StatusLog, error)
 	GetWorkersStatus(ctx context.Context) ([]*TrackingWorkerResponse, error)
+	ListOrders(ctx context.Context, filter OrderFilter) (*TrackingOrderList, error)
 }
 
 // Ответы Tracking Service
@@ -31,11 +69,19 @@ type TrackingOrderResponse struct {
 	UpdatedAt           time.Time
 	EstimatedCompletion *time.Time
 	ProcessedBy         *string
+	HandedOverAt        *time.Time
+}
+
+type TrackingOrderList struct {
+	Orders     []*domain.Order
+	NextCursor *OrderCursor
 }
 
 type TrackingWorkerResponse struct {
 	WorkerName      string
 	Status          domain.WorkerStatus
 	OrdersProcessed int
+	Capacity        int
+	CurrentLoad     int
 	LastSeen        time.Time
 }
@@ -0,0 +1,2 @@
+-- Time the order was served, picked up or delivered
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS handed_over_at TIMESTAMPTZ;
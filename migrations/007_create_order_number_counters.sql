@@ -0,0 +1,12 @@
+-- Create per-day order number counters
+CREATE TABLE IF NOT EXISTS order_number_counters (
+    day DATE PRIMARY KEY,
+    last_value BIGINT NOT NULL DEFAULT 0
+);
+
+-- Seed counters from existing orders so new numbers do not collide with old ones
+INSERT INTO order_number_counters (day, last_value)
+SELECT (created_at AT TIME ZONE 'UTC')::date, COUNT(*)
+FROM orders
+GROUP BY 1
+ON CONFLICT (day) DO NOTHING;
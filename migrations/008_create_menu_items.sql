@@ -0,0 +1,29 @@
+-- Create menu items table
+CREATE TABLE IF NOT EXISTS menu_items (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    price DECIMAL(8, 2) NOT NULL,
+    available BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_items_available ON menu_items (available);
+
+-- Order items reference the catalog; name and price are copied at order time
+ALTER TABLE order_items
+ADD COLUMN IF NOT EXISTS menu_item_id INTEGER REFERENCES menu_items (id) ON DELETE SET NULL;
+
+-- Initial menu
+INSERT INTO menu_items (name, description, category, price)
+SELECT * FROM (
+    VALUES
+        ('Margherita', 'Tomato sauce, mozzarella, basil', 'pizza', 12.99),
+        ('Pepperoni', 'Tomato sauce, mozzarella, pepperoni', 'pizza', 14.99),
+        ('Four Cheese', 'Mozzarella, gorgonzola, parmesan, fontina', 'pizza', 15.99),
+        ('Caesar Salad', 'Romaine, croutons, parmesan, caesar dressing', 'salad', 8.99),
+        ('Lemonade', 'House lemonade', 'drinks', 3.49)
+) AS seed (name, description, category, price)
+WHERE NOT EXISTS (SELECT 1 FROM menu_items);
@@ -0,0 +1,70 @@
+-- Create modifier groups (size, crust, toppings) for menu items
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    menu_item_id INTEGER NOT NULL REFERENCES menu_items (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    min_select INTEGER NOT NULL DEFAULT 0,
+    max_select INTEGER NOT NULL DEFAULT 1,
+    position INTEGER NOT NULL DEFAULT 0,
+    CHECK (min_select >= 0 AND max_select >= 1 AND min_select <= max_select)
+);
+
+-- Create modifiers with per-modifier price surcharge
+CREATE TABLE IF NOT EXISTS modifiers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    group_id INTEGER NOT NULL REFERENCES modifier_groups (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    price DECIMAL(8, 2) NOT NULL DEFAULT 0,
+    available BOOLEAN NOT NULL DEFAULT TRUE,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+-- Create order item modifiers; group, name and price are copied at order time
+CREATE TABLE IF NOT EXISTS order_item_modifiers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    order_item_id INTEGER NOT NULL REFERENCES order_items (id) ON DELETE CASCADE,
+    modifier_id INTEGER REFERENCES modifiers (id) ON DELETE SET NULL,
+    group_name TEXT NOT NULL,
+    name TEXT NOT NULL,
+    price DECIMAL(8, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_menu_item_id ON modifier_groups (menu_item_id);
+
+CREATE INDEX IF NOT EXISTS idx_modifiers_group_id ON modifiers (group_id);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_modifiers_order_item_id ON order_item_modifiers (order_item_id);
+
+-- Default pizza configuration: size, crust and toppings
+INSERT INTO modifier_groups (menu_item_id, name, min_select, max_select, position)
+SELECT m.id, g.name, g.min_select, g.max_select, g.position
+FROM menu_items m
+CROSS JOIN (
+    VALUES
+        ('Size', 1, 1, 1),
+        ('Crust', 1, 1, 2),
+        ('Toppings', 0, 5, 3)
+) AS g (name, min_select, max_select, position)
+WHERE m.category = 'pizza'
+  AND NOT EXISTS (SELECT 1 FROM modifier_groups);
+
+INSERT INTO modifiers (group_id, name, price, position)
+SELECT g.id, v.name, v.price, v.position
+FROM modifier_groups g
+JOIN (
+    VALUES
+        ('Size', 'Small', 0.00, 1),
+        ('Size', 'Medium', 3.00, 2),
+        ('Size', 'Large', 5.00, 3),
+        ('Crust', 'Classic', 0.00, 1),
+        ('Crust', 'Thin', 0.00, 2),
+        ('Crust', 'Cheese-stuffed', 2.50, 3),
+        ('Toppings', 'Extra cheese', 1.50, 1),
+        ('Toppings', 'Mushrooms', 1.00, 2),
+        ('Toppings', 'Jalapenos', 1.00, 3),
+        ('Toppings', 'No olives', 0.00, 4)
+) AS v (group_name, name, price, position) ON v.group_name = g.name
+WHERE NOT EXISTS (SELECT 1 FROM modifiers);
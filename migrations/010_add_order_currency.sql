@@ -0,0 +1,3 @@
+-- Amounts are exact decimals in the order currency
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1,20 @@
+-- Price breakdown: total_amount is the grand total
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS subtotal DECIMAL(10, 2),
+ADD COLUMN IF NOT EXISTS service_charge DECIMAL(10, 2) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS delivery_fee DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Orders created before pricing rules were charged the plain items sum
+UPDATE orders SET subtotal = total_amount WHERE subtotal IS NULL;
+
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    rate_bps INTEGER NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order_id ON order_tax_lines (order_id);
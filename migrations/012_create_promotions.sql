@@ -0,0 +1,45 @@
+-- Promotions (promo codes)
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    code TEXT UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL CHECK (
+        kind IN (
+            'percentage',
+            'fixed_amount',
+            'buy_x_get_y',
+            'free_delivery'
+        )
+    ),
+    percent_bps INTEGER NOT NULL DEFAULT 0,
+    amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    -- NULL means any item, so a referenced menu item cannot be deleted: SET NULL would widen the promotion
+    menu_item_id INTEGER REFERENCES menu_items (id) ON DELETE RESTRICT,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    free_quantity INTEGER NOT NULL DEFAULT 0,
+    min_subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    valid_from TIMESTAMPTZ,
+    valid_to TIMESTAMPTZ,
+    max_uses INTEGER,
+    max_uses_per_customer INTEGER,
+    times_used INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- One row per order that used a promo code; customer_key is the customer subject or normalized name (domain.PromotionCustomerKey)
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    promotion_id INTEGER NOT NULL REFERENCES promotions (id),
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    customer_key TEXT NOT NULL,
+    discount_amount DECIMAL(10, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_customer ON promotion_redemptions (promotion_id, customer_key);
+
+-- Discount applied to the order, see PriceBreakdown
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS discount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS promo_code TEXT;
@@ -0,0 +1,3 @@
+-- Request ID of the HTTP request that created the event, propagated to the AMQP message headers
+ALTER TABLE order_outbox
+ADD COLUMN IF NOT EXISTS request_id VARCHAR(128);
@@ -0,0 +1,3 @@
+-- W3C traceparent of the request that created the event, so the kitchen spans join its trace
+ALTER TABLE order_outbox
+ADD COLUMN IF NOT EXISTS traceparent VARCHAR(64);
@@ -0,0 +1,4 @@
+-- Oven slots of a kitchen worker and how many of them are busy right now
+ALTER TABLE workers
+ADD COLUMN IF NOT EXISTS capacity INTEGER NOT NULL DEFAULT 1,
+ADD COLUMN IF NOT EXISTS current_load INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Idempotency keys belong to the authenticated client that used them;
+-- an in-progress key left by a crashed request can be reclaimed after locked_until.
+-- lease_token identifies the request holding the key: only it may complete or release the key.
+ALTER TABLE idempotency_keys
+ADD COLUMN IF NOT EXISTS principal TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS lease_token TEXT;
+
+-- Keys left in progress before the lease existed become reclaimable
+UPDATE idempotency_keys
+SET locked_until = created_at + INTERVAL '1 minute'
+WHERE status = 'in_progress' AND locked_until IS NULL;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (principal, key);